@@ -7,6 +7,7 @@ import (
 	"golang.org/x/tools/go/packages"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/americanas-go/inject"
 	"github.com/americanas-go/log"
@@ -39,17 +40,53 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
-	graph, err := inject.NewGraphFromEntries(ctx, entries)
+	objects, err := inject.CollectObjects(basePath)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
 
-	generator := inject.NewGenerator(moduleName, graph)
-	err = generator.Generate(ctx)
+	resolver, err := inject.NewPackagesResolver(basePath)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
 
+	// types provided by third-party or hand-written fx code
+	var externals []inject.External
+	if _, err := os.Stat(filepath.Join(basePath, "inject.yaml")); err == nil {
+		externals, err = inject.LoadExternals(filepath.Join(basePath, "inject.yaml"))
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+	}
+
+	graphs, err := inject.NewGraphsFromEntries(ctx, entries, inject.WithObjects(objects), inject.WithResolver(resolver), inject.WithExternals(externals...))
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	testEntries, err := inject.CollectTestEntries(basePath)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	for profile, graph := range graphs {
+		generator := inject.NewGenerator(moduleName, graph, inject.ForProfile(profile))
+		err = generator.Generate(ctx)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+
+		overlay, err := inject.NewOverlayFromEntries(ctx, graph, testEntries, inject.WithResolver(resolver), inject.WithProfile(profile))
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+
+		err = generator.GenerateOverlay(ctx, overlay)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+	}
+
 	cmd := exec.Command("go", "mod", "tidy")
 	err = cmd.Run()
 	if err != nil {
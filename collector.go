@@ -1,15 +1,397 @@
 package inject
 
-import "github.com/americanas-go/annotation"
+import (
+	"bufio"
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 func CollectEntries(path string) ([]annotation.Entry, error) {
 	collector, err := annotation.Collect(
 		annotation.WithPath(path),
-		annotation.WithFilters("Inject", "Provide", "Invoke"),
+		annotation.WithFilters("Module", "Inject", "Provide", "Invoke", "Decorate", "Supply", "OnStart", "OnStop", "Private", "Primary"),
 	)
 	if err != nil {
 		return []annotation.Entry{}, err
 	}
 
-	return collector.Entries(), nil
+	values, err := CollectValues(path)
+	if err != nil {
+		return []annotation.Entry{}, err
+	}
+
+	return append(collector.Entries(), values...), nil
+}
+
+// CollectValues collects the package-level vars and consts annotated with
+// @Supply. Each value is described as an entry whose single result is the
+// value itself, so it can join the graph as a provider.
+func CollectValues(path string) ([]annotation.Entry, error) {
+	var entries []annotation.Entry
+	err := walkFiles(path, false, func(file string, module string, importPath string) error {
+		values, err := collectFileValues(file, module, importPath)
+		entries = append(entries, values...)
+		return err
+	})
+
+	return entries, err
+}
+
+// CollectTestEntries collects the functions and vars annotated with @Replace
+// in test files, along with the injects of those functions. Vars are described
+// as values, in the same form used by CollectValues.
+func CollectTestEntries(path string) ([]annotation.Entry, error) {
+	var entries []annotation.Entry
+	err := walkFiles(path, true, func(file string, module string, importPath string) error {
+		collected, err := collectTestFileEntries(file, module, importPath)
+		entries = append(entries, collected...)
+		return err
+	})
+
+	return entries, err
+}
+
+// CollectObjects collects the parameter and result structs, the ones
+// embedding fx.In or fx.Out, so the graph can expand their fields.
+func CollectObjects(path string) ([]Object, error) {
+	var objects []Object
+	err := walkFiles(path, false, func(file string, module string, importPath string) error {
+		collected, err := collectFileObjects(file, importPath)
+		objects = append(objects, collected...)
+		return err
+	})
+
+	return objects, err
+}
+
+// walkFiles calls collect for every go file under path, either the test files
+// or the other ones.
+func walkFiles(path string, test bool, collect func(file string, module string, importPath string) error) error {
+	module, err := getModulePath(path)
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if file != path && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "gen") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") != test {
+			return nil
+		}
+
+		rel, err := filepath.Rel(path, filepath.Dir(file))
+		if err != nil {
+			return err
+		}
+
+		importPath := module
+		if rel != "." {
+			importPath = strings.Join([]string{module, filepath.ToSlash(rel)}, "/")
+		}
+
+		return collect(file, module, importPath)
+	})
+}
+
+func collectFileValues(file string, module string, importPath string) ([]annotation.Entry, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectValues(f, file, module, importPath, AnnotationTypeSUPPLY.String()), nil
+}
+
+func collectTestFileEntries(file string, module string, importPath string) ([]annotation.Entry, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := collectValues(f, file, module, importPath, AnnotationTypeREPLACE.String())
+
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Doc == nil {
+			continue
+		}
+
+		var comments []string
+		for _, c := range fn.Doc.List {
+			comments = append(comments, c.Text)
+		}
+
+		annons := parseAnnotations(comments, AnnotationTypeREPLACE.String(), AnnotationTypeINJECT.String())
+		if len(annons) == 0 {
+			continue
+		}
+
+		entries = append(entries, annotation.Entry{
+			Comments: comments,
+			Module:   module,
+			File:     strings.TrimSuffix(filepath.Base(file), ".go"),
+			Path:     importPath,
+			Package:  f.Name.Name,
+			Func: annotation.Func{
+				Name:       fn.Name.Name,
+				Parameters: fieldTypes(fn.Type.Params),
+				Results:    fieldTypes(fn.Type.Results),
+			},
+			Annotations: annons,
+		})
+	}
+
+	return entries, nil
+}
+
+func collectFileObjects(file string, importPath string) ([]Object, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// fx may be imported under another name
+	fx := ""
+	for _, imp := range f.Imports {
+		if strings.Trim(imp.Path.Value, `"`) != "go.uber.org/fx" {
+			continue
+		}
+
+		fx = "fx"
+		if imp.Name != nil {
+			fx = imp.Name.Name
+		}
+	}
+
+	if fx == "" {
+		return nil, nil
+	}
+
+	var objects []Object
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			tspec := spec.(*ast.TypeSpec)
+			st, ok := tspec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			object := Object{
+				Path:    importPath,
+				Package: f.Name.Name,
+				File:    strings.TrimSuffix(filepath.Base(file), ".go"),
+				Name:    tspec.Name.Name,
+			}
+			embedded := false
+
+			for _, field := range st.Fields.List {
+				tp := types.ExprString(field.Type)
+
+				if len(field.Names) == 0 {
+					switch tp {
+					case fx + ".In":
+						embedded = true
+					case fx + ".Out":
+						embedded = true
+						object.Out = true
+					}
+					continue
+				}
+
+				var tag string
+				if field.Tag != nil {
+					tag, err = strconv.Unquote(field.Tag.Value)
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				for _, name := range field.Names {
+					object.Fields = append(object.Fields, Field{Name: name.Name, Type: tp, An: parseTag(tag)})
+				}
+			}
+
+			if embedded {
+				objects = append(objects, object)
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+// fieldTypes describes the fields of a function signature, one per name.
+func fieldTypes(fields *ast.FieldList) []annotation.Type {
+	if fields == nil {
+		return nil
+	}
+
+	var tps []annotation.Type
+	for _, field := range fields.List {
+		tp := types.ExprString(field.Type)
+		if len(field.Names) == 0 {
+			tps = append(tps, annotation.Type{Type: tp})
+			continue
+		}
+
+		for _, name := range field.Names {
+			tps = append(tps, annotation.Type{Name: name.Name, Type: tp})
+		}
+	}
+
+	return tps
+}
+
+// collectValues collects the package-level vars and consts of the file
+// annotated with the given names.
+func collectValues(f *ast.File, file string, module string, importPath string, names ...string) []annotation.Entry {
+	var entries []annotation.Entry
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || (gen.Tok != token.VAR && gen.Tok != token.CONST) {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			vspec := spec.(*ast.ValueSpec)
+
+			doc := vspec.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if doc == nil {
+				continue
+			}
+
+			var comments []string
+			for _, c := range doc.List {
+				comments = append(comments, c.Text)
+			}
+
+			annons := parseAnnotations(comments, names...)
+			if len(annons) == 0 {
+				continue
+			}
+
+			var tp string
+			if vspec.Type != nil {
+				tp = types.ExprString(vspec.Type)
+			}
+
+			for _, name := range vspec.Names {
+				entries = append(entries, annotation.Entry{
+					Comments:    comments,
+					Module:      module,
+					File:        strings.TrimSuffix(filepath.Base(file), ".go"),
+					Path:        importPath,
+					Package:     f.Name.Name,
+					Func:        annotation.Func{Name: name.Name, Results: []annotation.Type{{Name: name.Name, Type: tp}}},
+					Annotations: annons,
+				})
+			}
+		}
+	}
+
+	return entries
+}
+
+var annotationRegexp = regexp.MustCompile(`^//\s*@(\w+)\s*(?:\((.*)\))?\s*$`)
+
+// parseAnnotations parses the comment lines annotated with the given names,
+// in the same form used by the annotation collector.
+func parseAnnotations(comments []string, names ...string) []annotation.Annotation {
+	var annons []annotation.Annotation
+	for _, comment := range comments {
+		matches := annotationRegexp.FindStringSubmatch(strings.TrimSpace(comment))
+		if matches == nil {
+			continue
+		}
+
+		found := false
+		for _, name := range names {
+			if strings.EqualFold(matches[1], name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		value := strings.TrimSpace(matches[2])
+		values := make(map[string]interface{})
+		for _, attr := range strings.Split(value, ",") {
+			kv := strings.SplitN(strings.TrimSpace(attr), "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			values[strings.TrimSpace(kv[0])] = parseValue(strings.TrimSpace(kv[1]))
+		}
+
+		annons = append(annons, annotation.Annotation{
+			Name:  matches[1],
+			Value: value,
+			Map:   values,
+		})
+	}
+
+	return annons
+}
+
+func parseValue(value string) interface{} {
+	if i, err := strconv.Atoi(value); err == nil {
+		return i
+	}
+
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+
+	return value
+}
+
+// getModulePath reads the module path from the go.mod file found in path.
+func getModulePath(path string) (string, error) {
+	file, err := os.Open(filepath.Join(path, "go.mod"))
+	if err != nil {
+		return "", errors.NotFoundf("go.mod not found in %s", path)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`), nil
+		}
+	}
+
+	return "", errors.NotFoundf("module declaration not found in %s", filepath.Join(path, "go.mod"))
 }
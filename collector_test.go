@@ -0,0 +1,101 @@
+package inject
+
+import (
+	"github.com/americanas-go/annotation"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CollectorTestSuite struct {
+	suite.Suite
+}
+
+func TestCollectorTestSuite(t *testing.T) {
+	suite.Run(t, new(CollectorTestSuite))
+}
+
+func (suite *CollectorTestSuite) TestCollectValues() {
+	entries, err := CollectValues("testdata/inject/collect/values")
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name       string
+		value      string
+		tp         string
+		annotation map[string]interface{}
+	}{
+		{"Typed Var", "DefaultTimeout", "time.Duration", map[string]interface{}{"name": "defaultTimeout"}},
+		{"Untyped Const", "MaxRetries", "", map[string]interface{}{"name": "maxRetries", "type": "int"}},
+		{"Grouped Var Spec", "Region", "string", map[string]interface{}{"group": "regions"}},
+	}
+
+	suite.Require().Len(entries, len(testCases))
+
+	for i, tc := range testCases {
+		suite.Run(tc.name, func() {
+			entry := entries[i]
+			suite.Equal("github.com/acme/values/config", entry.Path)
+			suite.Equal("config", entry.Package)
+			suite.Equal(tc.value, entry.Func.Name)
+			suite.Equal(tc.tp, entry.Func.Results[0].Type)
+			suite.Require().Len(entry.Annotations, 1)
+			suite.Equal("Supply", entry.Annotations[0].Name)
+			suite.Equal(tc.annotation, entry.Annotations[0].Map)
+		})
+	}
+}
+
+func (suite *CollectorTestSuite) TestCollectTestEntries() {
+	entries, err := CollectTestEntries("testdata/inject/collect/replace")
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 2)
+
+	value := entries[0]
+	suite.Equal("FakeClient", value.Func.Name)
+	suite.Equal("db_test", value.File)
+	suite.True(isValue(value))
+	suite.Equal(map[string]interface{}{"target": "*Client"}, value.Annotations[0].Map)
+
+	fn := entries[1]
+	suite.Equal("newFakeCache", fn.Func.Name)
+	suite.False(isValue(fn))
+	suite.Equal([]annotation.Type{{Name: "client", Type: "*Client"}, {Name: "cfg", Type: "Config"}}, fn.Func.Parameters)
+	suite.Equal([]annotation.Type{{Type: "*Cache"}, {Type: "error"}}, fn.Func.Results)
+	suite.Require().Len(fn.Annotations, 2)
+	suite.Equal("Inject", fn.Annotations[0].Name)
+	suite.Equal("Replace", fn.Annotations[1].Name)
+}
+
+func (suite *CollectorTestSuite) TestCollectObjects() {
+	objects, err := CollectObjects("testdata/inject/collect/objects")
+	suite.Require().NoError(err)
+
+	suite.Equal([]Object{
+		{Path: "github.com/acme/objects/user", Package: "user", File: "params", Name: "Params", Fields: []Field{
+			{Name: "Reader", Type: "*Repo", An: Annotation{Name: "read"}},
+			{Name: "Cache", Type: "*Cache", An: Annotation{Optional: true}},
+			{Name: "Handlers", Type: "[]Handler", An: Annotation{Group: "handlers"}},
+		}},
+		{Path: "github.com/acme/objects/user", Package: "user", File: "params", Name: "Result", Out: true, Fields: []Field{
+			{Name: "Reader", Type: "*Repo", An: Annotation{Name: "rw"}},
+			{Name: "Writer", Type: "*Repo", An: Annotation{Name: "rw"}},
+			{Name: "Handlers", Type: "[]Handler", An: Annotation{Group: "handlers", Flatten: true}},
+		}},
+	}, objects)
+}
+
+func (suite *CollectorTestSuite) TestParseAnnotations() {
+	annons := parseAnnotations([]string{
+		"// Foo title",
+		"// @Provide (name=A, index=0)",
+		"// @Inject (optional=true, index=1)",
+		"// @Invoke",
+	}, "Provide", "Inject")
+
+	suite.Require().Len(annons, 2)
+	suite.Equal("Provide", annons[0].Name)
+	suite.Equal(map[string]interface{}{"name": "A", "index": 0}, annons[0].Map)
+	suite.Equal("Inject", annons[1].Name)
+	suite.Equal(map[string]interface{}{"optional": true, "index": 1}, annons[1].Map)
+}
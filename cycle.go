@@ -0,0 +1,196 @@
+package inject
+
+import (
+	"github.com/americanas-go/errors"
+	"sort"
+	"strings"
+)
+
+// Hop is an edge of a path through the graph, along with its attributes.
+type Hop[T any] struct {
+	From *Vertex[T]
+	To   *Vertex[T]
+	Attr EdgeAttr
+}
+
+// Cycle is a chain of hops ending at the vertex it starts from.
+type Cycle[T any] []Hop[T]
+
+// String renders the cycle with the types linking each hop, such as
+// a -(X)-> b -(Y)-> a.
+func (c Cycle[T]) String() string {
+	return Path[T](c).String()
+}
+
+// Cycles returns a cycle of every strongly connected component of the graph
+// with more than one vertex, starting from its smallest key, along with a
+// one-hop cycle of every vertex depending on itself. Each one is the shortest
+// cycle through that vertex, and they are sorted by their first key.
+func (g *Graph[T]) Cycles() []Cycle[T] {
+	var cycles []Cycle[T]
+	for _, component := range g.components() {
+		if len(component) < 2 && !g.selfLoop(component[0]) {
+			continue
+		}
+
+		cycles = append(cycles, g.cycleThrough(component))
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0].From.Key < cycles[j][0].From.Key
+	})
+
+	return cycles
+}
+
+// Validate reports the cycles of the graph, since fx can't build the
+// components that depend on themselves.
+func (g *Graph[T]) Validate() error {
+	cycles := g.Cycles()
+	if len(cycles) == 0 {
+		return nil
+	}
+
+	var descs []string
+	for _, cycle := range cycles {
+		descs = append(descs, cycle.String())
+	}
+
+	return errors.NotValidf("dependency cycle found: %s", strings.Join(descs, "; "))
+}
+
+// components returns the strongly connected components of the graph, found by
+// Tarjan's algorithm. Keys and adjacencies are visited in order, so the result
+// does not depend on the order of the maps.
+func (g *Graph[T]) components() [][]string {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var connect func(key string)
+	connect = func(key string) {
+		index[key] = len(index)
+		low[key] = index[key]
+		stack = append(stack, key)
+		onStack[key] = true
+
+		for _, next := range g.adjacentKeys(key) {
+			if _, visited := index[next]; !visited {
+				connect(next)
+				low[key] = min(low[key], low[next])
+			} else if onStack[next] {
+				low[key] = min(low[key], index[next])
+			}
+		}
+
+		if low[key] != index[key] {
+			return
+		}
+
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == key {
+				break
+			}
+		}
+		sort.Strings(component)
+		components = append(components, component)
+	}
+
+	for _, key := range g.sortedKeys() {
+		if _, visited := index[key]; !visited {
+			connect(key)
+		}
+	}
+
+	return components
+}
+
+// selfLoop reports whether the vertex has an edge to itself.
+func (g *Graph[T]) selfLoop(key string) bool {
+	_, ok := g.attrs[key][key]
+	return ok
+}
+
+// cycleThrough returns the shortest cycle through the first vertex of a
+// strongly connected component, walking only the vertices of the component.
+func (g *Graph[T]) cycleThrough(component []string) Cycle[T] {
+	start := component[0]
+	members := make(map[string]bool)
+	for _, key := range component {
+		members[key] = true
+	}
+
+	parent := map[string]string{start: ""}
+	queue := []string{start}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		for _, next := range g.adjacentKeys(key) {
+			if !members[next] {
+				continue
+			}
+
+			if next == start {
+				return g.hops(parent, key, start)
+			}
+
+			if _, ok := parent[next]; !ok {
+				parent[next] = key
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return nil
+}
+
+// hops builds the chain from start to last, following the parents found by a
+// breadth-first search, closed by the edge from last back to start.
+func (g *Graph[T]) hops(parent map[string]string, last string, start string) Cycle[T] {
+	keys := []string{start}
+	for key := last; key != start; key = parent[key] {
+		keys = append([]string{key}, keys...)
+	}
+	keys = append([]string{start}, keys...)
+
+	var cycle Cycle[T]
+	for i := 0; i < len(keys)-1; i++ {
+		cycle = append(cycle, Hop[T]{
+			From: g.vertices[keys[i]],
+			To:   g.vertices[keys[i+1]],
+			Attr: g.attrs[keys[i]][keys[i+1]],
+		})
+	}
+
+	return cycle
+}
+
+// sortedKeys returns the keys of the vertices in order.
+func (g *Graph[T]) sortedKeys() []string {
+	keys := make([]string, 0, len(g.vertices))
+	for key := range g.vertices {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// adjacentKeys returns the keys of the vertices adjacent to a vertex, in
+// the order of its adjacency list.
+func (g *Graph[T]) adjacentKeys(key string) []string {
+	keys := make([]string, 0, len(g.edges[key]))
+	for _, v := range g.edges[key] {
+		keys = append(keys, v.Key)
+	}
+
+	return keys
+}
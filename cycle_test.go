@@ -0,0 +1,61 @@
+package inject
+
+import (
+	"github.com/americanas-go/errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CycleTestSuite struct {
+	suite.Suite
+}
+
+func TestCycleTestSuite(t *testing.T) {
+	suite.Run(t, new(CycleTestSuite))
+}
+
+func (suite *CycleTestSuite) TestCycles() {
+	testCases := []struct {
+		name     string
+		edges    [][2]string
+		expected []string
+	}{
+		{"Acyclic", [][2]string{{"a", "b"}, {"b", "c"}, {"a", "c"}}, nil},
+		{"Two Vertices", [][2]string{{"a", "b"}, {"b", "a"}}, []string{
+			"a -(ab)-> b -(ba)-> a",
+		}},
+		{"Shortest Cycle Through The First Vertex", [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}, {"d", "a"}, {"b", "d"}}, []string{
+			"a -(ab)-> b -(bd)-> d -(da)-> a",
+		}},
+		{"Self Dependency", [][2]string{{"a", "b"}, {"b", "b"}}, []string{
+			"b -(bb)-> b",
+		}},
+		{"Separate Cycles", [][2]string{{"x", "y"}, {"y", "x"}, {"y", "a"}, {"a", "b"}, {"b", "a"}}, []string{
+			"a -(ab)-> b -(ba)-> a",
+			"x -(xy)-> y -(yx)-> x",
+		}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			var cycles []string
+			for _, cycle := range newGraph(tc.edges).Cycles() {
+				cycles = append(cycles, cycle.String())
+			}
+			suite.Equal(tc.expected, cycles)
+		})
+	}
+}
+
+func (suite *CycleTestSuite) TestValidate() {
+	suite.NoError(newGraph([][2]string{{"a", "b"}}).Validate())
+
+	err := newGraph([][2]string{{"a", "b"}, {"b", "a"}}).Validate()
+	suite.IsType(errors.NotValidf(""), err)
+	suite.Contains(err.Error(), "a -(ab)-> b -(ba)-> a")
+
+	err = newGraph([][2]string{{"a", "a"}}).Validate()
+	suite.IsType(errors.NotValidf(""), err)
+	suite.Contains(err.Error(), "a -(aa)-> a")
+}
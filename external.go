@@ -0,0 +1,129 @@
+package inject
+
+import (
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// External is a type provided outside of the scanned code, either by fx
+// itself, by third-party fx modules or by hand-written fx code.
+type External struct {
+	Type  string `yaml:"type"` // type qualified by its import path, such as *go.uber.org/zap.Logger.
+	Name  string `yaml:"name"`
+	Group string `yaml:"group"`
+}
+
+// Builtins are the types fx provides to every application.
+var Builtins = []External{
+	{Type: "go.uber.org/fx.Lifecycle"},
+	{Type: "go.uber.org/fx.Shutdowner"},
+	{Type: "go.uber.org/fx.DotGraph"},
+}
+
+// WithExternals registers types provided outside of the scanned code, so the
+// components depending on them are satisfied. The fx built-ins are always
+// registered.
+func WithExternals(externals ...External) GraphOption {
+	return func(o *graphOptions) {
+		o.externals = append(o.externals, externals...)
+	}
+}
+
+// LoadExternals reads the externals declared by a yaml config file, such as:
+//
+//	externals:
+//	  - type: "*go.uber.org/zap.Logger"
+//	  - type: net/http.Handler
+//	    group: routes
+func LoadExternals(file string) ([]External, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.NotFoundf("the externals config %s can't be read: %v", file, err)
+	}
+
+	var config struct {
+		Externals []External `yaml:"externals"`
+	}
+
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return nil, errors.NotValidf("the externals config %s is invalid: %v", file, err)
+	}
+
+	for _, external := range config.Externals {
+		if external.Type == "" {
+			return nil, errors.NotValidf("the type is required on the externals of the config %s", file)
+		}
+	}
+
+	return config.Externals, nil
+}
+
+// majorVersionRegexp matches the major version suffixes of import paths, such
+// as the v5 of github.com/jackc/pgx/v5 or the .v3 of gopkg.in/yaml.v3.
+var majorVersionRegexp = regexp.MustCompile(`(^|\.)v[0-9]+$`)
+
+// packageName returns the name a package is conventionally declared with,
+// which is the last element of its import path without the major version.
+func packageName(importPath string) string {
+	name := majorVersionRegexp.ReplaceAllString(path.Base(importPath), "")
+	if name == "" && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+
+	return name
+}
+
+// dropImportPaths replaces the import paths qualifying the names of a type by
+// the names of their packages.
+func dropImportPaths(tp string) string {
+	return qualifiedNameRegexp.ReplaceAllStringFunc(tp, func(name string) string {
+		match := qualifiedNameRegexp.FindStringSubmatch(name)
+		return packageName(match[1]) + "." + match[2]
+	})
+}
+
+// ids returns the identities of the external, qualified either by the import
+// path or by the name of its package, as the resolvers do.
+func (e External) ids() []string {
+	an := Annotation{Name: e.Name, Group: e.Group}
+	short := dropImportPaths(e.Type)
+
+	return []string{
+		strings.Join([]string{e.Type, an.ID()}, "_"),
+		strings.Join([]string{short, an.ID()}, "_"),
+	}
+}
+
+// component describes the external as the component of a vertex, named after
+// its type and qualifier.
+func (e External) component() Component {
+	tp := strings.TrimLeft(e.Type, "*[]")
+
+	pkg, name := "", tp
+	if i := strings.LastIndex(tp, "."); i >= 0 {
+		pkg, name = tp[:i], tp[i+1:]
+	}
+
+	if e.Name != "" {
+		name += "_" + e.Name
+	} else if e.Group != "" {
+		name += "_" + e.Group
+	}
+
+	return Component{
+		Entry: annotation.Entry{
+			Path:    pkg,
+			Package: packageName(pkg),
+			Func:    annotation.Func{Name: name},
+		},
+		An:       Annotation{Name: e.Name, Group: e.Group},
+		Provides: e.ids()[:1],
+		External: true,
+	}
+}
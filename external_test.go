@@ -0,0 +1,102 @@
+package inject
+
+import (
+	"context"
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExternalTestSuite struct {
+	suite.Suite
+}
+
+func TestExternalTestSuite(t *testing.T) {
+	suite.Run(t, new(ExternalTestSuite))
+}
+
+func (suite *ExternalTestSuite) TestLoadExternals() {
+	externals, err := LoadExternals("testdata/inject/externals/inject.yaml")
+	suite.NoError(err)
+	suite.Equal([]External{
+		{Type: "*go.uber.org/zap.Logger"},
+		{Type: "net/http.Handler", Group: "routes"},
+	}, externals)
+
+	_, err = LoadExternals("testdata/inject/externals/invalid.yaml")
+	suite.IsType(errors.NotValidf(""), err)
+
+	_, err = LoadExternals("testdata/inject/externals/missing.yaml")
+	suite.IsType(errors.NotFoundf(""), err)
+}
+
+func (suite *ExternalTestSuite) TestIDs() {
+	testCases := []struct {
+		name     string
+		external External
+		expected []string
+	}{
+		{"Builtin", External{Type: "go.uber.org/fx.Lifecycle"}, []string{"go.uber.org/fx.Lifecycle_default", "fx.Lifecycle_default"}},
+		{"Named Pointer", External{Type: "*go.uber.org/zap.Logger", Name: "sugar"}, []string{"*go.uber.org/zap.Logger_named_sugar", "*zap.Logger_named_sugar"}},
+		{"Group", External{Type: "net/http.Handler", Group: "routes"}, []string{"net/http.Handler_grouped_routes", "http.Handler_grouped_routes"}},
+		{"Dotted Import Path", External{Type: "*gopkg.in/yaml.v3.Node"}, []string{"*gopkg.in/yaml.v3.Node_default", "*yaml.Node_default"}},
+		{"Major Version", External{Type: "github.com/jackc/pgx/v5.Conn"}, []string{"github.com/jackc/pgx/v5.Conn_default", "pgx.Conn_default"}},
+		{"Composite", External{Type: "[]*go.uber.org/zap.Logger"}, []string{"[]*go.uber.org/zap.Logger_default", "[]*zap.Logger_default"}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.Equal(tc.expected, tc.external.ids())
+		})
+	}
+}
+
+func (suite *ExternalTestSuite) TestGraph() {
+	data, err := os.ReadFile("testdata/inject/mkgraph/34_external_success.yaml")
+	suite.Require().NoError(err)
+
+	var entries []annotation.Entry
+	suite.Require().NoError(yaml.Unmarshal(data, &entries))
+
+	_, err = NewGraphFromEntries(context.Background(), entries)
+	suite.IsType(errors.NotFoundf(""), err)
+
+	externals, err := LoadExternals("testdata/inject/externals/inject.yaml")
+	suite.Require().NoError(err)
+
+	graph, err := NewGraphFromEntries(context.Background(), entries, WithExternals(externals...))
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name         string
+		key          string
+		expectedKeys []string
+	}{
+		{"Third-Party Types", "github.com/acme/app/server_NewServer", []string{
+			"go.uber.org/zap_Logger",
+			"net/http_Handler_routes",
+		}},
+		{"Builtin Types", "github.com/acme/app/server_Start", []string{
+			"go.uber.org/fx_Lifecycle",
+			"github.com/acme/app/server_NewServer",
+		}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			var keys []string
+			for _, v := range graph.vertices[tc.key].Incoming() {
+				keys = append(keys, v.Key)
+				if v.Key != "github.com/acme/app/server_NewServer" {
+					suite.True(v.Value.External)
+					suite.Nil(v.Value.Module)
+				}
+			}
+			suite.ElementsMatch(tc.expectedKeys, keys)
+		})
+	}
+}
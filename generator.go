@@ -7,27 +7,86 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"go/ast"
 	"go/format"
+	"go/parser"
+	"go/types"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"text/template"
 )
 
 type Generator struct {
 	moduleName string
 	graph      *Graph[Component]
+	profile    string
+	scopes     map[string]*Module
 }
 
-func NewGenerator(moduleName string, graph *Graph[Component]) *Generator {
-	return &Generator{
+// GeneratorOption configures the generator.
+type GeneratorOption func(*Generator)
+
+// ForProfile generates the modules of the graph of a profile, suffixed by the
+// profile, such as NewFooModule_prod, so the modules of every profile live
+// side by side and the application picks the ones of its profile.
+func ForProfile(profile string) GeneratorOption {
+	return func(p *Generator) {
+		p.profile = profile
+	}
+}
+
+func NewGenerator(moduleName string, graph *Graph[Component], opts ...GeneratorOption) *Generator {
+	generator := &Generator{
 		moduleName: moduleName,
 		graph:      graph,
 	}
+	for _, opt := range opts {
+		opt(generator)
+	}
+
+	return generator
 }
 
 func (p *Generator) Generate(ctx context.Context) error {
+	sorted, err := p.graph.TopologicalSort()
+	if err != nil {
+		return err
+	}
+
+	p.scopes = scopes(p.graph)
+
+	var packages []*Module
+	modules := make(map[string]*Module)
+	members := make(map[string][]*Vertex[Component])
+	for _, key := range p.graph.sortedKeys() {
+		module, ok := p.scopes[key]
+		if !ok {
+			continue
+		}
+
+		// the modules of the private providers are not validated by the graph
+		name := module.Entry.Path + "." + module.Name
+		if _, ok := modules[name]; !ok && p.graph.vertices[key].Value.Module == nil {
+			packages = append(packages, module)
+		}
+		modules[name] = module
+		members[name] = append(members[name], p.graph.vertices[key])
+	}
+
+	err = validateModuleNames(p.graph, packages)
+	if err != nil {
+		return err
+	}
 
-	for _, vert := range p.graph.VerticesWithNoIncomingEdges() {
+	err = p.validateIncludes(modules, members)
+	if err != nil {
+		return err
+	}
+
+	for _, vert := range sorted {
 		err := p.generateModuleFile(ctx, vert)
 		if err != nil {
 			log.Errorf("Error generating module file: %v", err)
@@ -35,15 +94,38 @@ func (p *Generator) Generate(ctx context.Context) error {
 		}
 	}
 
+	for name, module := range modules {
+		// decorators and private providers come first, so they are applied to
+		// the scope of the whole module
+		sort.Slice(members[name], func(i, j int) bool {
+			di, dj := scoped(members[name][i]), scoped(members[name][j])
+			if di != dj {
+				return di
+			}
+			return members[name][i].Key < members[name][j].Key
+		})
+
+		err := p.generateNamedModuleFile(ctx, module, members[name])
+		if err != nil {
+			log.Errorf("Error generating module file: %v", err)
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (p *Generator) generateModuleFile(ctx context.Context, vertex *Vertex[Component]) error {
+	// external components have no module of their own
+	if vertex.Value.External {
+		return nil
+	}
+
 	annoEntry := vertex.Value
 	entry := annoEntry.Entry
 
 	packageName := filepath.Base(entry.Path)
-	funcName := entry.Func.Name
+	funcName := getName(entry)
 
 	data := ModuleData{
 		PackageName:  packageName,
@@ -52,93 +134,700 @@ func (p *Generator) generateModuleFile(ctx context.Context, vertex *Vertex[Compo
 		Alias:        generateAlias(entry.Path),
 		Entry:        entry,
 		Type:         getType(entry.Annotations),
+		Private:      isPrivate(entry),
+		Suffix:       p.suffix(),
+	}
+	data.Target = getTarget(entry, data.Alias)
+
+	// generic functions are explicitly instantiated
+	if args := typeArgs(entry); len(args) > 0 {
+		var tps []string
+		for _, arg := range args {
+			tp, imports, err := p.qualify(annoEntry, data.Alias, arg.Type)
+			if err != nil {
+				return err
+			}
+			tps = append(tps, tp)
+			data.TypeImports = append(data.TypeImports, imports...)
+		}
+		data.Target += "[" + strings.Join(tps, ", ") + "]"
+	}
+
+	paramTags, resultTags, err := getTags(entry)
+	if err != nil {
+		return err
+	}
+	data.ParamTags = paramTags
+	data.ResultTags = resultTags
+
+	as, asSelf, typeImports, err := p.getAs(annoEntry, data.Alias)
+	if err != nil {
+		return err
+	}
+	data.As = as
+	data.AsSelf = asSelf
+	data.TypeImports = uniqueImports(append(data.TypeImports, typeImports...))
+
+	if data.Type == AnnotationTypeSUPPLY.String() {
+		supply, err := getSupply(entry)
+		if err != nil {
+			return err
+		}
+		data.Supply = supply
+
+		// untyped constants are converted to the declared type
+		if supply.Type != "" {
+			tp, imports, err := p.qualify(annoEntry, data.Alias, supply.Type)
+			if err != nil {
+				return err
+			}
+			data.Target = conversion(tp, data.Target)
+			data.TypeImports = uniqueImports(append(data.TypeImports, imports...))
+		}
+	}
+
+	hook, err := getHook(entry)
+	if err != nil {
+		return err
+	}
+
+	if hook != nil {
+		hookData, imports, err := p.getHookData(annoEntry, data.Alias, hook)
+		if err != nil {
+			return err
+		}
+		data.Hook = hookData
+		data.TypeImports = uniqueImports(append(data.TypeImports, imports...))
 	}
 
 	// Rastrear as importações únicas
 	uniqueImports := make(map[string]struct{})
 
-	// Processar cada vértice adjacente
-	for _, v := range vertex.Incoming() {
+	for _, include := range p.includes(vertex) {
+		var alias string
+		if include.Path != entry.Path {
+			importPath := strings.ReplaceAll(include.Path, "github.com/", "")
+			fullImportPath := p.moduleName + "/gen/inject/" + importPath
+			alias = generateAlias(fullImportPath)
 
-		entry := v.Value.Entry
+			if _, exists := uniqueImports[alias]; !exists {
+				uniqueImports[alias] = struct{}{}
+				data.Imports = append(data.Imports, ImportData{Alias: alias, Path: fullImportPath, Entry: include.Entry})
+			}
+		}
 
-		var alias string
-		if entry.Package != packageName {
-			alias = generateAlias(entry.Path)
+		data.Modules = append(data.Modules, ImportData{Alias: alias, Name: include.Name, Entry: include.Entry})
+	}
+
+	formatted, err := p.render(data)
+	if err != nil {
+		return err
+	}
+
+	return p.writeFile(entry.Path, funcName+data.Suffix, formatted)
+}
+
+// includes returns the modules included by the module of the vertex, one for
+// each of its dependencies. Members of a named module, or of the module of the
+// private providers of a package, are only included by that module, so the
+// ones of other modules are reached through theirs, and the decorators and
+// private providers of their own module are already included ahead of them.
+// Paths are the ones of the packages, not of the generated modules.
+func (p *Generator) includes(vertex *Vertex[Component]) []ImportData {
+	// dependencies are listed by key so the generated file is stable
+	deps := vertex.Incoming()
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].Key < deps[j].Key
+	})
+	switch getType(vertex.Value.Entry.Annotations) {
+	case AnnotationTypeINVOKE.String(), AnnotationTypeONSTART.String(), AnnotationTypeONSTOP.String():
+		deps = append(decoratorsOf(vertex), deps...)
+	}
+
+	var includes []ImportData
+	seen := make(map[string]struct{})
+	for _, v := range deps {
+		if v.Value.External {
+			continue
 		}
 
-		data.Modules = append(data.Modules, ImportData{Alias: alias, Entry: entry})
+		include := ImportData{Path: v.Value.Entry.Path, Name: getName(v.Value.Entry), Entry: v.Value.Entry}
+		if module := p.scopes[v.Key]; module != nil {
+			if !sameModule(module, p.scopes[vertex.Key]) {
+				include = ImportData{Path: module.Entry.Path, Name: getModuleName(module), Entry: module.Entry}
+			} else if scoped(v) {
+				continue
+			}
+		}
 
-		if entry.Package == packageName {
+		if _, ok := seen[include.Path+"."+include.Name]; ok {
 			continue
 		}
+		seen[include.Path+"."+include.Name] = struct{}{}
 
-		importPath := strings.ReplaceAll(entry.Path, "github.com/", "")
-		fullImportPath := p.moduleName + "/gen/inject/" + importPath
+		includes = append(includes, include)
+	}
+
+	return includes
+}
 
-		if _, exists := uniqueImports[alias]; !exists {
-			uniqueImports[alias] = struct{}{}
-			data.Imports = append(data.Imports, ImportData{Alias: alias, Path: fullImportPath, Entry: entry})
+// validateIncludes ensures that the generated modules don't include each
+// other, which happens when named modules depend on each other even though
+// their members don't. Each module runs once, so it would never return.
+func (p *Generator) validateIncludes(modules map[string]*Module, members map[string][]*Vertex[Component]) error {
+	includes := NewGraph[string]()
+	add := func(path, name string) string {
+		key := path + "." + name + "Module"
+		if _, ok := includes.vertices[key]; !ok {
+			includes.AddVertex(key, key)
 		}
+		return key
 	}
 
-	tmpl, err := NewTemplate()
-	if err != nil {
-		return fmt.Errorf("error parsing template: %v", err)
+	for _, vertex := range p.graph.vertices {
+		if vertex.Value.External {
+			continue
+		}
+
+		key := add(vertex.Value.Entry.Path, getName(vertex.Value.Entry))
+		for _, include := range p.includes(vertex) {
+			includes.AddEdge(add(include.Path, include.Name), key)
+		}
+	}
+
+	for name, module := range modules {
+		key := add(module.Entry.Path, getModuleName(module))
+		for _, v := range members[name] {
+			includes.AddEdge(add(v.Value.Entry.Path, getName(v.Value.Entry)), key)
+		}
+	}
+
+	cycles := includes.Cycles()
+	if len(cycles) == 0 {
+		return nil
+	}
+
+	keys := []string{cycles[0][0].From.Key}
+	for _, hop := range cycles[0] {
+		keys = append(keys, hop.To.Key)
 	}
 
-	repoPath := strings.ReplaceAll(entry.Path, "github.com/", "")
-	fileName := fmt.Sprintf("%s_module.go", strings.ToLower(funcName))
-	filePath := filepath.Join("gen", "inject", repoPath, fileName)
+	return errors.NotValidf("the generated modules include each other: %s", strings.Join(keys, " -> "))
+}
+
+// generateNamedModuleFile generates the named fx module bundling the modules of its members.
+func (p *Generator) generateNamedModuleFile(ctx context.Context, module *Module, members []*Vertex[Component]) error {
+	data := NamedModuleData{
+		PackageName: filepath.Base(module.Entry.Path),
+		Name:        module.Name,
+		ModuleName:  getModuleName(module),
+		Suffix:      p.suffix(),
+	}
+
+	uniqueImports := make(map[string]struct{})
+
+	for _, v := range members {
+		entry := v.Value.Entry
+
+		var alias string
+		if entry.Path != module.Entry.Path {
+			importPath := strings.ReplaceAll(entry.Path, "github.com/", "")
+			fullImportPath := p.moduleName + "/gen/inject/" + importPath
+			alias = generateAlias(fullImportPath)
+
+			if _, exists := uniqueImports[alias]; !exists {
+				uniqueImports[alias] = struct{}{}
+				data.Imports = append(data.Imports, ImportData{Alias: alias, Path: fullImportPath, Entry: entry})
+			}
+		}
 
-	err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+		data.Modules = append(data.Modules, ImportData{Alias: alias, Name: getName(entry), Entry: entry})
+	}
+
+	tmpl, err := NewNamedModuleTemplate()
 	if err != nil {
-		return fmt.Errorf("error creating directories: %v", err)
+		return err
 	}
 
-	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, data)
+	formatted, err := execute(tmpl, data)
 	if err != nil {
-		return fmt.Errorf("error executing template: %v", err)
+		return err
+	}
+
+	return p.writeFile(module.Entry.Path, data.ModuleName+data.Suffix, formatted)
+}
+
+// GenerateOverlay generates, for each package declaring replacements, a test
+// file with the options replacing their targets.
+func (p *Generator) GenerateOverlay(ctx context.Context, overlay *Overlay) error {
+	var keys []string
+	packages := make(map[string][]Replacement)
+	for _, replacement := range overlay.Replacements {
+		entry := replacement.Entry
+		key := entry.Path + ":" + entry.Package
+		if _, ok := packages[key]; !ok {
+			keys = append(keys, key)
+		}
+		packages[key] = append(packages[key], replacement)
+	}
+
+	for _, key := range keys {
+		err := p.generateReplaceFile(ctx, packages[key])
+		if err != nil {
+			log.Errorf("Error generating replace file: %v", err)
+			return err
+		}
 	}
 
-	formatted, err := format.Source(buf.Bytes())
+	return nil
+}
+
+// generateReplaceFile generates the test file of the replacements declared in
+// a single package, next to the files declaring them.
+func (p *Generator) generateReplaceFile(ctx context.Context, replacements []Replacement) error {
+	entry := replacements[0].Entry
+
+	data := ReplaceModuleData{PackageName: entry.Package, Suffix: p.suffix()}
+
+	sort.Slice(replacements, func(i, j int) bool {
+		return gid(replacements[i].Entry) < gid(replacements[j].Entry)
+	})
+
+	for _, replacement := range replacements {
+		replace := ReplaceData{
+			Target: replacement.Entry.Func.Name,
+			Value:  isValue(replacement.Entry),
+			An:     replacement.An,
+		}
+
+		if !replace.Value {
+			params, _, err := getTags(replacement.Entry)
+			if err != nil {
+				return err
+			}
+			replace.ParamTags = params
+			replace.ResultTags = quoteTags([]string{replacement.An.Tag()})
+		}
+
+		data.Replacements = append(data.Replacements, replace)
+	}
+
+	tmpl, err := NewReplaceTemplate()
 	if err != nil {
 		return err
 	}
 
+	formatted, err := execute(tmpl, data)
+	if err != nil {
+		return err
+	}
+
+	dir := strings.TrimPrefix(strings.TrimPrefix(entry.Path, entry.Module), "/")
+	fileName := fmt.Sprintf("%s_replace%s_test.go", strings.ToLower(entry.Package), strings.ToLower(data.Suffix))
+
+	return write(filepath.Join(dir, fileName), formatted)
+}
+
+func (p *Generator) writeFile(path string, name string, content []byte) error {
+	repoPath := strings.ReplaceAll(path, "github.com/", "")
+	fileName := fmt.Sprintf("%s_module.go", strings.ToLower(name))
+
+	return write(filepath.Join("gen", "inject", repoPath, fileName), content)
+}
+
+func write(filePath string, content []byte) error {
+	err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error creating directories: %v", err)
+	}
+
 	file, err := os.Create(filePath)
 	if err != nil {
 		return fmt.Errorf("error creating file: %v", err)
 	}
 	defer file.Close()
 
-	_, err = file.Write(formatted)
+	_, err = file.Write(content)
+	return err
+}
+
+func (p *Generator) render(data ModuleData) ([]byte, error) {
+	tmpl, err := NewTemplate()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, v := range vertex.Adjacent() {
-		err := p.generateModuleFile(ctx, v)
-		if err != nil {
-			return fmt.Errorf("error generating module file: %v", err)
-		}
+	return execute(tmpl, data)
+}
+
+func execute(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("error executing template: %v", err)
 	}
 
-	return nil
+	return format.Source(buf.Bytes())
 }
 
+// generateAlias returns the import alias of a package path. The hash is
+// prefixed, since an identifier can't start with a digit.
 func generateAlias(packagePath string) string {
 	hash := md5.Sum([]byte(packagePath))
-	return hex.EncodeToString(hash[:])
+	return "x" + hex.EncodeToString(hash[:])
+}
+
+// getName returns the name of the module generated for the entry. Methods are
+// prefixed by their receiver type.
+func getName(entry annotation.Entry) string {
+	return strings.TrimPrefix(entry.Struct, "*") + entry.Func.Name + instanceName(entry)
+}
+
+// suffix returns the suffix of the generated modules of the profile, if any.
+func (p *Generator) suffix() string {
+	if p.profile == "" {
+		return ""
+	}
+
+	return "_" + p.profile
+}
+
+// getTarget returns the expression referencing the entry function. Methods are
+// referenced by a method expression, so the receiver is taken as the first parameter.
+func getTarget(entry annotation.Entry, alias string) string {
+	if entry.Struct == "" {
+		return fmt.Sprintf("%s.%s", alias, entry.Func.Name)
+	}
+
+	if strings.HasPrefix(entry.Struct, "*") {
+		return fmt.Sprintf("(*%s.%s).%s", alias, strings.TrimPrefix(entry.Struct, "*"), entry.Func.Name)
+	}
+
+	return fmt.Sprintf("%s.%s.%s", alias, entry.Struct, entry.Func.Name)
+}
+
+// conversion returns the expression converting the value to the type. Types
+// starting with an operator are parenthesized.
+func conversion(tp string, value string) string {
+	if strings.HasPrefix(tp, "*") || strings.HasPrefix(tp, "<-") || strings.HasPrefix(tp, "func") {
+		tp = "(" + tp + ")"
+	}
+
+	return fmt.Sprintf("%s(%s)", tp, value)
+}
+
+// getSupply returns the supply annotation of a value entry.
+func getSupply(entry annotation.Entry) (Annotation, error) {
+	a := Annotation{}
+	for _, ann := range entry.Annotations {
+		if strings.ToUpper(ann.Name) == AnnotationTypeSUPPLY.String() {
+			err := ann.Decode(&a)
+			return a, err
+		}
+	}
+
+	return a, nil
+}
+
+// getModuleName returns the exported name of a named module.
+func getModuleName(module *Module) string {
+	return strings.ToUpper(module.Name[:1]) + module.Name[1:]
+}
+
+// sameModule reports whether both modules are the same module.
+func sameModule(a, b *Module) bool {
+	return a != nil && b != nil && a.Name == b.Name && a.Entry.Path == b.Entry.Path
+}
+
+// scopes returns the module bundling each vertex, by key. Besides the named
+// modules, the private providers not bundled by one are bundled along with
+// their consumers by a module of their package, since fx.Private only
+// applies to the fx.Module providing them. Each provider is then provided
+// once, visible to every consumer.
+func scopes(graph *Graph[Component]) map[string]*Module {
+	scopes := make(map[string]*Module)
+	packages := make(map[string]*Module)
+	for _, key := range graph.sortedKeys() {
+		vertex := graph.vertices[key]
+		if vertex.Value.Module != nil {
+			scopes[key] = vertex.Value.Module
+			continue
+		}
+
+		if vertex.Value.External || !isPrivate(vertex.Value.Entry) {
+			continue
+		}
+
+		entry := vertex.Value.Entry
+		module, ok := packages[entry.Path]
+		if !ok {
+			module = &Module{
+				Name:  entry.Package,
+				Scope: ModuleAttrPATH,
+				Entry: annotation.Entry{Module: entry.Module, Path: entry.Path, Package: entry.Package},
+			}
+			packages[entry.Path] = module
+		}
+
+		// private providers are only visible to consumers of their package
+		// not bundled by a named module
+		scopes[key] = module
+		for _, v := range vertex.Adjacent() {
+			scopes[v.Key] = module
+		}
+	}
+
+	return scopes
+}
+
+// scoped reports whether the vertex must be included at the level of its named
+// module, instead of the module of its first consumer.
+func scoped(vertex *Vertex[Component]) bool {
+	return getType(vertex.Value.Entry.Annotations) == AnnotationTypeDECORATE.String() || isPrivate(vertex.Value.Entry)
+}
+
+// decoratorsOf returns the decorators the vertex transitively depends on that
+// are not bundled by a named module. Invokes include them first, so the
+// decorations apply to the root scope instead of the first consumer module.
+func decoratorsOf(vertex *Vertex[Component]) []*Vertex[Component] {
+	var decorators []*Vertex[Component]
+
+	visited := map[string]struct{}{vertex.Key: {}}
+	queue := []*Vertex[Component]{vertex}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, v := range current.Incoming() {
+			if _, ok := visited[v.Key]; ok {
+				continue
+			}
+			visited[v.Key] = struct{}{}
+			queue = append(queue, v)
+
+			if v.Value.Module == nil && getType(v.Value.Entry.Annotations) == AnnotationTypeDECORATE.String() {
+				decorators = append(decorators, v)
+			}
+		}
+	}
+
+	sort.Slice(decorators, func(i, j int) bool {
+		return decorators[i].Key < decorators[j].Key
+	})
+
+	return decorators
 }
 
 func getType(annons []annotation.Annotation) string {
 	for _, ann := range annons {
-		if strings.ToUpper(ann.Name) == AnnotationTypeINVOKE.String() {
+		switch strings.ToUpper(ann.Name) {
+		case AnnotationTypeINVOKE.String():
 			return AnnotationTypeINVOKE.String()
+		case AnnotationTypeDECORATE.String():
+			return AnnotationTypeDECORATE.String()
+		case AnnotationTypeSUPPLY.String():
+			return AnnotationTypeSUPPLY.String()
+		case AnnotationTypeONSTART.String():
+			return AnnotationTypeONSTART.String()
+		case AnnotationTypeONSTOP.String():
+			return AnnotationTypeONSTOP.String()
 		}
 	}
 
 	return AnnotationTypePROVIDE.String()
 }
+
+// getTags returns the positional fx param and result tags of the entry,
+// built from its inject and provide annotations.
+func getTags(entry annotation.Entry) ([]string, []string, error) {
+	params := make([]string, len(entry.Func.Parameters))
+	results := make([]string, len(entry.Func.Results))
+
+	for _, ann := range entry.Annotations {
+		annType, err := ParseAnnotationType(strings.ToUpper(ann.Name))
+		if err != nil {
+			continue
+		}
+
+		a, err := decodeAnnotation(entry, ann)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if a.Index == nil {
+			continue
+		}
+
+		switch annType {
+		case AnnotationTypePROVIDE:
+			if *a.Index >= 0 && *a.Index < len(results) {
+				results[*a.Index] = a.Tag()
+			}
+		case AnnotationTypeINJECT:
+			if *a.Index >= 0 && *a.Index < len(params) {
+				params[*a.Index] = a.Tag()
+			}
+		case AnnotationTypeDECORATE:
+			if *a.Index >= 0 && *a.Index < len(results) {
+				results[*a.Index] = a.Tag()
+				if param := decoratedParam(entry, *a.Index); param >= 0 {
+					params[param] = a.Tag()
+				}
+			}
+		}
+	}
+
+	// the receiver of a method is the first parameter of its method expression
+	if entry.Struct != "" {
+		params = append([]string{""}, params...)
+	}
+
+	return quoteTags(params), quoteTags(results), nil
+}
+
+// quoteTags renders the tags as raw string literals, dropping the trailing
+// empty ones. It returns nil when no tag is set.
+func quoteTags(tags []string) []string {
+	last := -1
+	for i, tag := range tags {
+		if tag != "" {
+			last = i
+		}
+	}
+
+	var quoted []string
+	for _, tag := range tags[:last+1] {
+		quoted = append(quoted, "`"+tag+"`")
+	}
+
+	return quoted
+}
+
+// getAs returns the positional fx.As targets of the component results along
+// with the imports they require. The bool reports whether the results must
+// also be provided as themselves.
+func (p *Generator) getAs(component Component, alias string) ([]string, bool, []ImportData, error) {
+	entry := component.Entry
+	targets := make([]string, len(entry.Func.Results))
+	self := false
+
+	var imports []ImportData
+
+	for _, ann := range entry.Annotations {
+		if strings.ToUpper(ann.Name) != AnnotationTypePROVIDE.String() {
+			continue
+		}
+
+		a, err := decodeAnnotation(entry, ann)
+		if err != nil {
+			return nil, false, nil, err
+		}
+
+		if a.As == "" || a.Index == nil || *a.Index < 0 || *a.Index >= len(targets) {
+			continue
+		}
+
+		tp, imps, err := p.qualify(component, alias, a.As)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		imports = append(imports, imps...)
+
+		targets[*a.Index] = fmt.Sprintf("new(%s)", tp)
+		self = self || a.Self
+	}
+
+	last := -1
+	for i, target := range targets {
+		if target != "" {
+			last = i
+		}
+	}
+
+	var as []string
+	for _, target := range targets[:last+1] {
+		if target == "" {
+			target = "fx.Self()"
+		}
+		as = append(as, target)
+	}
+
+	return as, self, uniqueImports(imports), nil
+}
+
+// getHookData returns the data used to append the hook to the fx.Lifecycle,
+// along with the imports required by the bound type. Methods are bound through
+// a method value, while functions are wrapped in a closure.
+func (p *Generator) getHookData(component Component, alias string, hook *Hook) (*HookData, []ImportData, error) {
+	tp, imports, err := p.qualify(component, alias, hook.Type)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := &HookData{Event: hook.Event, Type: tp}
+	if hook.Index < 0 {
+		data.Func = "r." + component.Entry.Func.Name
+		return data, imports, nil
+	}
+
+	data.Func = fmt.Sprintf("func(ctx context.Context) error { return %s.%s(ctx, r) }", alias, component.Entry.Func.Name)
+	data.Context = true
+
+	return data, imports, nil
+}
+
+// qualify rewrites a type as written in the annotations of the component to be
+// referenced from its generated module, along with the imports it requires.
+// The import paths of the types are the ones resolved by the graph.
+func (p *Generator) qualify(component Component, alias string, tp string) (string, []ImportData, error) {
+	entry := component.Entry
+
+	qualified, ok := component.Types[tp]
+	if !ok {
+		return "", nil, errors.NotFoundf("the type %s is not resolved in the entry %s.%s", tp, entry.Path, entry.Func.Name)
+	}
+
+	replaced, paths := splitImportPaths(qualified)
+	expr, err := parser.ParseExpr(replaced)
+	if err != nil {
+		return "", nil, errors.NotValidf("the type %s is invalid in the entry %s.%s: %v", tp, entry.Path, entry.Func.Name, err)
+	}
+
+	var imports []ImportData
+	expr = rewriteNames(expr, func(name string) ast.Expr {
+		idx := strings.LastIndex(name, ".")
+		if idx < 0 {
+			return nil
+		}
+
+		path, ok := paths[name[:idx]]
+		if !ok {
+			return nil
+		}
+
+		if path == entry.Path {
+			return ast.NewIdent(alias + "." + name[idx+1:])
+		}
+
+		typeAlias := generateAlias(path)
+		imports = append(imports, ImportData{Alias: typeAlias, Path: path})
+		return ast.NewIdent(typeAlias + "." + name[idx+1:])
+	})
+
+	return types.ExprString(expr), uniqueImports(imports), nil
+}
+
+// uniqueImports removes the repeated imports, keeping their order.
+func uniqueImports(imports []ImportData) []ImportData {
+	var unique []ImportData
+	seen := make(map[string]struct{})
+	for _, imp := range imports {
+		if _, ok := seen[imp.Alias]; ok {
+			continue
+		}
+		seen[imp.Alias] = struct{}{}
+		unique = append(unique, imp)
+	}
+
+	return unique
+}
@@ -1 +1,522 @@
 package inject
+
+import (
+	"context"
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"go/token"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GeneratorTestSuite struct {
+	suite.Suite
+	entries map[string]annotation.Entry
+}
+
+func TestGeneratorTestSuite(t *testing.T) {
+	suite.Run(t, new(GeneratorTestSuite))
+}
+
+func (suite *GeneratorTestSuite) SetupSuite() {
+	suite.entries = make(map[string]annotation.Entry)
+	for _, entry := range suite.loadEntries("1_success.yaml") {
+		suite.entries[entry.Func.Name] = entry
+	}
+}
+
+func (suite *GeneratorTestSuite) loadEntries(id string) []annotation.Entry {
+	data, err := os.ReadFile(filepath.Join("testdata/inject/mkgraph", id))
+	suite.Require().NoError(err)
+
+	var entries []annotation.Entry
+	suite.Require().NoError(yaml.Unmarshal(data, &entries))
+
+	return entries
+}
+
+func (suite *GeneratorTestSuite) TestGetTags() {
+	testCases := []struct {
+		name           string
+		funcName       string
+		expectedParams []string
+		expectedResult []string
+	}{
+		{"Provide Without Qualifier", "FooBar", nil, nil},
+		{"Named Provide", "FooBaz", nil, []string{"`name:\"A\"`"}},
+		{"Named Provide With Default Inject", "Foo", nil, []string{"`name:\"A\"`"}},
+		{"Named Injects Before Default Ones", "Foz", []string{"`name:\"A\"`", "`name:\"A\"`"}, nil},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			params, results, err := getTags(suite.entries[tc.funcName])
+			suite.NoError(err)
+			suite.Equal(tc.expectedParams, params)
+			suite.Equal(tc.expectedResult, results)
+		})
+	}
+}
+
+func (suite *GeneratorTestSuite) TestGetTagsMultipleResults() {
+	entries := suite.loadEntries("9_multiple_results_success.yaml")
+
+	_, results, err := getTags(entries[0])
+	suite.NoError(err)
+	suite.Equal([]string{"`name:\"read\"`", "`name:\"write\"`"}, results)
+}
+
+func (suite *GeneratorTestSuite) TestGenerateAlias() {
+	for _, path := range []string{"github.com/acme/app/cache", "github.com/acme/app/order", "github.com/acme/app/user"} {
+		suite.True(token.IsIdentifier(generateAlias(path)), path)
+	}
+	suite.Equal(generateAlias("github.com/acme/app/cache"), generateAlias("github.com/acme/app/cache"))
+	suite.NotEqual(generateAlias("github.com/acme/app/cache"), generateAlias("github.com/acme/app/order"))
+}
+
+func (suite *GeneratorTestSuite) TestMethod() {
+	entries := suite.loadEntries("11_method_success.yaml")
+	method := entries[1]
+
+	suite.Equal("FactoryNewClient", getName(method))
+	suite.Equal("(*a.Factory).NewClient", getTarget(method, "a"))
+	suite.Equal("NewFactory", getName(entries[0]))
+	suite.Equal("a.NewFactory", getTarget(entries[0], "a"))
+
+	params, _, err := getTags(method)
+	suite.NoError(err)
+	suite.Equal([]string{"``", "`name:\"cfg\"`"}, params)
+}
+
+func (suite *GeneratorTestSuite) TestNamedModule() {
+	tmpl, err := NewNamedModuleTemplate()
+	suite.Require().NoError(err)
+
+	out, err := execute(tmpl, NamedModuleData{
+		PackageName: "payments",
+		Name:        "payments",
+		ModuleName:  getModuleName(&Module{Name: "payments"}),
+		Modules: []ImportData{
+			{Name: "NewGateway"},
+			{Alias: "b", Name: "NewCharger"},
+		},
+		Imports: []ImportData{{Alias: "b", Path: "github.com/acme/app/gen/inject/acme/app/billing"}},
+	})
+	suite.Require().NoError(err)
+
+	suite.Contains(string(out), "func PaymentsModule() fx.Option {")
+	suite.Contains(string(out), "fx.Module(\"payments\",")
+	suite.Contains(string(out), "NewGatewayModule(),")
+	suite.Contains(string(out), "b.NewChargerModule(),")
+}
+
+func (suite *GeneratorTestSuite) TestNamedModuleConsumers() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.loadEntries("12_module_success.yaml"))
+	suite.Require().NoError(err)
+
+	suite.chdir()
+	suite.Require().NoError(NewGenerator("github.com/acme/app", graph).Generate(context.Background()))
+
+	// members are only included by their named module
+	testCases := []struct {
+		name        string
+		file        string
+		contains    []string
+		notContains []string
+	}{
+		{"Consumer Outside The Modules", "gen/inject/acme/app/cmd/serve_module.go",
+			[]string{generateAlias("github.com/acme/app/gen/inject/acme/app/payments") + ".ChargesModule(),"},
+			[]string{"NewChargerModule()"}},
+		{"Member Of Another Module", "gen/inject/acme/app/payments/newcharger_module.go",
+			[]string{"PaymentsModule(),"},
+			[]string{"NewGatewayModule()"}},
+		{"Named Module", "gen/inject/acme/app/payments/payments_module.go",
+			[]string{"fx.Module(\"payments\",", "NewGatewayModule(),"},
+			nil},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			out, err := os.ReadFile(tc.file)
+			suite.Require().NoError(err)
+			for _, s := range tc.contains {
+				suite.Contains(string(out), s)
+			}
+			for _, s := range tc.notContains {
+				suite.NotContains(string(out), s)
+			}
+		})
+	}
+}
+
+func (suite *GeneratorTestSuite) TestNamedModuleIncludeCycle() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.loadEntries("39_module_include_cycle.yaml"))
+	suite.Require().NoError(err)
+
+	suite.chdir()
+	err = NewGenerator("github.com/acme/app", graph).Generate(context.Background())
+	suite.Require().Error(err)
+	suite.IsType(errors.NotValidf(""), err)
+	suite.Contains(err.Error(), "github.com/acme/app/alpha.AlphaModule")
+}
+
+func (suite *GeneratorTestSuite) TestDecoratorsOf() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.loadEntries("14_decorate_success.yaml"))
+	suite.Require().NoError(err)
+
+	var keys []string
+	for _, v := range decoratorsOf(graph.vertices["github.com/acme/app/cmd_Fetch"]) {
+		keys = append(keys, v.Key)
+	}
+	suite.Equal([]string{"github.com/acme/app/client_WithTracing"}, keys)
+	suite.Equal(AnnotationTypeDECORATE.String(), getType(graph.vertices["github.com/acme/app/client_WithTracing"].Value.Entry.Annotations))
+}
+
+func (suite *GeneratorTestSuite) TestGetAs() {
+	entries := suite.loadEntries("8_as_success.yaml")
+	graph, err := NewGraphFromEntries(context.Background(), entries)
+	suite.Require().NoError(err)
+
+	generator := NewGenerator("github.com/acme/app", graph)
+	domain := generateAlias("github.com/acme/app/domain")
+
+	testCases := []struct {
+		name     string
+		entry    annotation.Entry
+		expected []string
+		self     bool
+		imports  int
+	}{
+		{"Interface By Package Name", entries[0], []string{"new(" + domain + ".Repository)"}, false, 1},
+		{"Interface By Import Path With Self", entries[1], []string{"new(" + domain + ".Cache)"}, true, 1},
+		{"Without Interface", entries[2], nil, false, 0},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			as, self, imports, err := generator.getAs(graph.vertices[gid(tc.entry)].Value, generateAlias(tc.entry.Path))
+			suite.NoError(err)
+			suite.Equal(tc.expected, as)
+			suite.Equal(tc.self, self)
+			suite.Len(imports, tc.imports)
+		})
+	}
+}
+
+func (suite *GeneratorTestSuite) TestGetAsUnknownPackage() {
+	entries := suite.loadEntries("8_as_success.yaml")
+
+	// the types are resolved by the graph
+	_, _, _, err := NewGenerator("github.com/acme/app", nil).getAs(Component{Entry: entries[0]}, generateAlias(entries[0].Path))
+	suite.Error(err)
+	suite.IsType(errors.NotFoundf("tipo de erro esperado"), err)
+}
+
+func (suite *GeneratorTestSuite) TestGetHookData() {
+	entries := suite.loadEntries("18_hook_success.yaml")
+	graph, err := NewGraphFromEntries(context.Background(), entries)
+	suite.Require().NoError(err)
+
+	generator := NewGenerator("github.com/acme/app", graph)
+	db := generateAlias("github.com/acme/app/db")
+	server := generateAlias("github.com/acme/app/server")
+
+	testCases := []struct {
+		name     string
+		entry    annotation.Entry
+		expected HookData
+		imported bool
+	}{
+		{"Method", entries[1], HookData{Event: "OnStop", Type: "*" + db + ".DB", Func: "r.Close"}, false},
+		{"Function", entries[3], HookData{Event: "OnStart", Type: "*" + server + ".Server",
+			Func: "func(ctx context.Context) error { return " + server + ".Listen(ctx, r) }", Context: true}, false},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			hook, err := getHook(tc.entry)
+			suite.Require().NoError(err)
+
+			data, imports, err := generator.getHookData(graph.vertices[gid(tc.entry)].Value, generateAlias(tc.entry.Path), hook)
+			suite.NoError(err)
+			suite.Equal(tc.expected, *data)
+			suite.Equal(tc.imported, len(imports) > 0)
+		})
+	}
+}
+
+func (suite *GeneratorTestSuite) TestRender() {
+	testCases := []struct {
+		name        string
+		data        ModuleData
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "Provide Without Tags",
+			data: ModuleData{PackageName: "simple", FunctionName: "FooBar", Target: "a.FooBar", ImportPath: "github.com/acme/simple", Alias: "a", Type: "PROVIDE"},
+			contains: []string{
+				"fx.Provide(",
+				"a.FooBar,",
+			},
+			notContains: []string{"fx.Annotate("},
+		},
+		{
+			name: "Provide With Result Tags",
+			data: ModuleData{PackageName: "simple", FunctionName: "FooBaz", Target: "a.FooBaz", ImportPath: "github.com/acme/simple", Alias: "a", Type: "PROVIDE",
+				ResultTags: []string{"`name:\"A\"`"}},
+			contains: []string{
+				"fx.Annotate(",
+				"a.FooBaz,",
+				"fx.ResultTags(`name:\"A\"`),",
+			},
+			notContains: []string{"fx.ParamTags("},
+		},
+		{
+			name: "Invoke With Param Tags",
+			data: ModuleData{PackageName: "simple", FunctionName: "Foz", Target: "a.Foz", ImportPath: "github.com/acme/simple", Alias: "a", Type: "INVOKE",
+				ParamTags: []string{"`name:\"A\"`", "``", "`group:\"g\"`"}},
+			contains: []string{
+				"fx.Invoke(",
+				"fx.ParamTags(`name:\"A\"`, ``, `group:\"g\"`),",
+			},
+			notContains: []string{"fx.ResultTags("},
+		},
+		{
+			name: "Decorate",
+			data: ModuleData{PackageName: "client", FunctionName: "WithTracing", Target: "a.WithTracing", ImportPath: "github.com/acme/client", Alias: "a", Type: "DECORATE"},
+			contains: []string{
+				"options = fx.Options(",
+				"fx.Decorate(",
+				"a.WithTracing,",
+			},
+			notContains: []string{"fx.Module(", "fx.Provide("},
+		},
+		{
+			name: "Supply Named Value",
+			data: ModuleData{PackageName: "config", FunctionName: "DefaultTimeout", Target: "a.DefaultTimeout", ImportPath: "github.com/acme/config", Alias: "a", Type: "SUPPLY",
+				Supply: Annotation{Name: "defaultTimeout"}},
+			contains: []string{
+				"fx.Module(\"DefaultTimeout\",",
+				"fx.Supply(",
+				"Name:   \"defaultTimeout\",",
+				"Target: a.DefaultTimeout,",
+			},
+			notContains: []string{"fx.Provide(", "Group:"},
+		},
+		{
+			name: "Provide As Interface",
+			data: ModuleData{PackageName: "postgres", FunctionName: "NewRepo", Target: "a.NewRepo", ImportPath: "github.com/acme/postgres", Alias: "a", Type: "PROVIDE",
+				As: []string{"new(b.Repository)"}, AsSelf: true, TypeImports: []ImportData{{Alias: "b", Path: "github.com/acme/domain"}}},
+			contains: []string{
+				"b \"github.com/acme/domain\"",
+				"fx.As(new(b.Repository)),",
+				"fx.As(fx.Self()),",
+			},
+		},
+		{
+			name: "Private Provide",
+			data: ModuleData{PackageName: "client", FunctionName: "NewConfig", Target: "a.NewConfig", ImportPath: "github.com/acme/client", Alias: "a", Type: "PROVIDE", Private: true},
+			contains: []string{
+				"options = fx.Options(",
+				"a.NewConfig,",
+				"fx.Private,",
+			},
+			notContains: []string{"fx.Module("},
+		},
+		{
+			name: "Provide For A Profile",
+			data: ModuleData{PackageName: "queue", FunctionName: "NewQueue", Target: "a.NewQueue", ImportPath: "github.com/acme/queue", Alias: "a", Type: "PROVIDE", Suffix: "_prod",
+				Modules: []ImportData{{Name: "NewConfig", Path: "github.com/acme/queue", Alias: "b"}}},
+			contains: []string{
+				"var NewQueueOnce_prod sync.Once",
+				"func NewQueueModule_prod() fx.Option {",
+				"b.NewConfigModule_prod(),",
+			},
+			notContains: []string{"NewQueueModule()"},
+		},
+		{
+			name: "Method Hook",
+			data: ModuleData{PackageName: "db", FunctionName: "DBClose", Target: "(*a.DB).Close", ImportPath: "github.com/acme/db", Alias: "a", Type: "ONSTOP",
+				Hook: &HookData{Event: "OnStop", Type: "*a.DB", Func: "r.Close"}},
+			contains: []string{
+				"options = fx.Options(",
+				"fx.Invoke(",
+				"func(lc fx.Lifecycle, r *a.DB) {",
+				"lc.Append(fx.Hook{OnStop: r.Close})",
+			},
+			notContains: []string{"fx.Annotate(", "\"context\""},
+		},
+		{
+			name: "Function Hook With Param Tags",
+			data: ModuleData{PackageName: "server", FunctionName: "Listen", Target: "a.Listen", ImportPath: "github.com/acme/server", Alias: "a", Type: "ONSTART",
+				ParamTags: []string{"``", "`name:\"http\"`"},
+				Hook:      &HookData{Event: "OnStart", Type: "*a.Server", Func: "func(ctx context.Context) error { return a.Listen(ctx, r) }", Context: true}},
+			contains: []string{
+				"\"context\"",
+				"fx.Annotate(",
+				"func(lc fx.Lifecycle, r *a.Server) {",
+				"OnStart: func(ctx context.Context) error { return a.Listen(ctx, r) }",
+				"fx.ParamTags(``, `name:\"http\"`),",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			out, err := NewGenerator("github.com/acme", nil).render(tc.data)
+			suite.Require().NoError(err)
+
+			for _, s := range tc.contains {
+				suite.Contains(string(out), s)
+			}
+			for _, s := range tc.notContains {
+				suite.NotContains(string(out), s)
+			}
+		})
+	}
+}
+
+func (suite *GeneratorTestSuite) TestReplaceModule() {
+	tmpl, err := NewReplaceTemplate()
+	suite.Require().NoError(err)
+
+	out, err := execute(tmpl, ReplaceModuleData{
+		PackageName: "db",
+		Replacements: []ReplaceData{
+			{Target: "FakeClient", Value: true},
+			{Target: "FakeReader", Value: true, An: Annotation{Name: "read"}},
+			{Target: "newFakeCache", ParamTags: []string{"``", "`name:\"cfg\"`"}, ResultTags: []string{"`name:\"cache\"`"}},
+		},
+	})
+	suite.Require().NoError(err)
+
+	for _, s := range []string{
+		"func ReplaceModule() fx.Option {",
+		"fx.Replace(\n\t\t\tFakeClient,\n\t\t),",
+		"Name:   \"read\",",
+		"Target: FakeReader,",
+		"fx.Decorate(",
+		"newFakeCache,",
+		"fx.ParamTags(``, `name:\"cfg\"`),",
+		"fx.ResultTags(`name:\"cache\"`),",
+	} {
+		suite.Contains(string(out), s)
+	}
+	suite.NotContains(string(out), "sync.Once")
+}
+
+func (suite *GeneratorTestSuite) TestPrivateProviderConsumers() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.loadEntries("40_private_consumers.yaml"))
+	suite.Require().NoError(err)
+
+	suite.chdir()
+	suite.Require().NoError(NewGenerator("github.com/acme/app", graph).Generate(context.Background()))
+
+	// the private config is provided once, by the module of its package
+	testCases := []struct {
+		name        string
+		file        string
+		contains    []string
+		notContains []string
+	}{
+		{"Private Provider", "gen/inject/acme/app/client/newconfig_module.go",
+			[]string{"var NewConfigOnce sync.Once", "fx.Private,"},
+			nil},
+		{"Package Module", "gen/inject/acme/app/client/client_module.go",
+			[]string{"fx.Module(\"client\",\n\t\t\tNewConfigModule(),\n\t\t\tNewClientModule(),\n\t\t\tRunAModule(),\n\t\t\tRunBModule(),"},
+			nil},
+		{"Provider Consumer", "gen/inject/acme/app/client/newclient_module.go",
+			nil,
+			[]string{"NewConfigModule()"}},
+		{"First Invoke Consumer", "gen/inject/acme/app/client/runa_module.go",
+			nil,
+			[]string{"NewConfigModule()"}},
+		{"Second Invoke Consumer", "gen/inject/acme/app/client/runb_module.go",
+			nil,
+			[]string{"NewConfigModule()"}},
+		{"Consumer Outside The Package", "gen/inject/acme/app/cmd/serve_module.go",
+			[]string{generateAlias("github.com/acme/app/gen/inject/acme/app/client") + ".ClientModule(),"},
+			[]string{"NewClientModule()"}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			out, err := os.ReadFile(tc.file)
+			suite.Require().NoError(err)
+			for _, s := range tc.contains {
+				suite.Contains(string(out), s)
+			}
+			for _, s := range tc.notContains {
+				suite.NotContains(string(out), s)
+			}
+		})
+	}
+}
+
+func (suite *GeneratorTestSuite) TestSupplyType() {
+	entries := suite.loadEntries("16_supply_success.yaml")
+	graph, err := NewGraphFromEntries(context.Background(), entries)
+	suite.Require().NoError(err)
+
+	suite.chdir()
+	suite.Require().NoError(NewGenerator("github.com/acme/app", graph).Generate(context.Background()))
+
+	out, err := os.ReadFile("gen/inject/acme/app/config/maxretries_module.go")
+	suite.Require().NoError(err)
+	suite.Contains(string(out), "Target: int("+generateAlias("github.com/acme/app/config")+".MaxRetries),")
+
+	suite.Equal("time.Duration(a.Timeout)", conversion("time.Duration", "a.Timeout"))
+	suite.Equal("(*a.Config)(a.Default)", conversion("*a.Config", "a.Default"))
+}
+
+func (suite *GeneratorTestSuite) TestGenericInstance() {
+	entries := suite.loadEntries("27_generic_success.yaml")
+	graph, err := NewGraphFromEntries(context.Background(), entries)
+	suite.Require().NoError(err)
+
+	suite.chdir()
+	suite.Require().NoError(NewGenerator("github.com/acme/app", graph).Generate(context.Background()))
+
+	cache := generateAlias("github.com/acme/app/cache")
+	testCases := []struct {
+		name     string
+		file     string
+		contains []string
+	}{
+		{"User", "newstoreuseruser_module.go", []string{
+			"func NewStoreUserUserModule() fx.Option {",
+			cache + ".NewStore[" + generateAlias("github.com/acme/app/user") + ".User],",
+			generateAlias("github.com/acme/app/user") + " \"github.com/acme/app/user\"",
+		}},
+		{"Order", "newstoreorderorder_module.go", []string{
+			"func NewStoreOrderOrderModule() fx.Option {",
+			cache + ".NewStore[" + generateAlias("github.com/acme/app/order") + ".Order],",
+			generateAlias("github.com/acme/app/order") + " \"github.com/acme/app/order\"",
+		}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			out, err := os.ReadFile(filepath.Join("gen/inject/acme/app/cache", tc.file))
+			suite.Require().NoError(err)
+			for _, s := range tc.contains {
+				suite.Contains(string(out), s)
+			}
+		})
+	}
+}
+
+// chdir moves the test to a temporary directory, where the modules are generated.
+func (suite *GeneratorTestSuite) chdir() {
+	wd, err := os.Getwd()
+	suite.Require().NoError(err)
+	suite.Require().NoError(os.Chdir(suite.T().TempDir()))
+	suite.T().Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
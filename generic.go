@@ -0,0 +1,198 @@
+package inject
+
+import (
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"golang.org/x/tools/go/ast/astutil"
+	"strings"
+	"unicode"
+)
+
+// TypeArg binds a type parameter of a generic function to a type argument.
+type TypeArg struct {
+	Param string
+	Type  string
+}
+
+// parseInstance parses the instance parameter of an annotation, a list of
+// type arguments such as T:User;ID:int, in the order of the type parameters.
+func parseInstance(instance string) ([]TypeArg, error) {
+	var args []TypeArg
+	for _, pair := range strings.Split(instance, ";") {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return nil, errors.NotValidf("the instance %s is invalid, use the form T:User;ID:int", instance)
+		}
+
+		args = append(args, TypeArg{Param: strings.TrimSpace(kv[0]), Type: strings.TrimSpace(kv[1])})
+	}
+
+	return args, nil
+}
+
+// instanceOf returns the instance parameter of the entry, or an empty string
+// when it does not instantiate a generic function.
+func instanceOf(entry annotation.Entry) string {
+	for _, ann := range entry.Annotations {
+		if v, ok := ann.Map["instance"].(string); ok && v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// typeArgs returns the type arguments the entry instantiates its function with.
+func typeArgs(entry annotation.Entry) []TypeArg {
+	args, _ := parseInstance(instanceOf(entry))
+	return args
+}
+
+// instantiate expands an entry of a generic function into one entry per
+// instance declared by its annotations. Each of them keeps the annotations
+// bound to its instance, along with the ones shared by every instance, and
+// has the type parameters of its signature replaced by the type arguments.
+func instantiate(entry annotation.Entry) ([]annotation.Entry, error) {
+	var instances []string
+	seen := make(map[string]struct{})
+	for _, ann := range entry.Annotations {
+		if v, ok := ann.Map["instance"].(string); ok && v != "" {
+			if _, ok := seen[v]; !ok {
+				seen[v] = struct{}{}
+				instances = append(instances, v)
+			}
+		}
+	}
+
+	if len(instances) == 0 {
+		return []annotation.Entry{entry}, nil
+	}
+
+	if entry.Struct != "" {
+		return nil, errors.NotValidf("the instance parameter is only allowed on functions, found in the entry %s.%s", entry.Path, entry.Func.Name)
+	}
+
+	var entries []annotation.Entry
+	for _, instance := range instances {
+		args, err := parseInstance(instance)
+		if err != nil {
+			return nil, err
+		}
+
+		inst := entry
+		inst.Annotations = nil
+		for _, ann := range entry.Annotations {
+			if v, ok := ann.Map["instance"].(string); !ok || v == "" || v == instance {
+				inst.Annotations = append(inst.Annotations, ann)
+			}
+		}
+
+		inst.Func.Parameters, err = substituteTypes(entry.Func.Parameters, args)
+		if err != nil {
+			return nil, err
+		}
+
+		inst.Func.Results, err = substituteTypes(entry.Func.Results, args)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, inst)
+	}
+
+	return entries, nil
+}
+
+func substituteTypes(tps []annotation.Type, args []TypeArg) ([]annotation.Type, error) {
+	var substituted []annotation.Type
+	for _, tp := range tps {
+		s, err := substitute(tp.Type, args)
+		if err != nil {
+			return nil, err
+		}
+		substituted = append(substituted, annotation.Type{Name: tp.Name, Type: s})
+	}
+
+	return substituted, nil
+}
+
+// substitute replaces the type parameters in a type by their type arguments.
+func substitute(tp string, args []TypeArg) (string, error) {
+	expr, err := parser.ParseExpr(tp)
+	if err != nil {
+		return "", errors.NotValidf("the type %s is invalid: %v", tp, err)
+	}
+
+	replacements := make(map[string]ast.Expr)
+	for _, arg := range args {
+		replacement, err := parser.ParseExpr(arg.Type)
+		if err != nil {
+			return "", errors.NotValidf("the type argument %s is invalid: %v", arg.Type, err)
+		}
+		replacements[arg.Param] = replacement
+	}
+
+	expr = rewriteNames(expr, func(name string) ast.Expr {
+		return replacements[name]
+	})
+
+	return types.ExprString(expr), nil
+}
+
+// rewriteNames replaces the type names of a type expression, either plain or
+// qualified by a package name. The name is kept when replace returns nil.
+func rewriteNames(expr ast.Expr, replace func(name string) ast.Expr) ast.Expr {
+	root := astutil.Apply(expr, func(c *astutil.Cursor) bool {
+		var name string
+		switch node := c.Node().(type) {
+		case *ast.SelectorExpr:
+			pkg, ok := node.X.(*ast.Ident)
+			if !ok {
+				return false
+			}
+			name = pkg.Name + "." + node.Sel.Name
+		case *ast.Ident:
+			// the names of fields and parameters of struct and func types
+			if _, ok := c.Parent().(*ast.Field); ok && c.Name() == "Names" {
+				return true
+			}
+			name = node.Name
+		default:
+			return true
+		}
+
+		if replacement := replace(name); replacement != nil {
+			c.Replace(replacement)
+		}
+
+		return false
+	}, nil)
+
+	return root.(ast.Expr)
+}
+
+// instanceName returns the type arguments of the entry as an identifier, to
+// tell apart the modules generated for each instance.
+func instanceName(entry annotation.Entry) string {
+	var name strings.Builder
+	for _, arg := range typeArgs(entry) {
+		upper := true
+		for _, r := range arg.Type {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				upper = true
+				continue
+			}
+
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			name.WriteRune(r)
+		}
+	}
+
+	return name.String()
+}
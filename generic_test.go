@@ -0,0 +1,67 @@
+package inject
+
+import (
+	"github.com/americanas-go/annotation"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GenericTestSuite struct {
+	suite.Suite
+}
+
+func TestGenericTestSuite(t *testing.T) {
+	suite.Run(t, new(GenericTestSuite))
+}
+
+func (suite *GenericTestSuite) TestSubstitute() {
+	testCases := []struct {
+		name     string
+		tp       string
+		args     []TypeArg
+		expected string
+	}{
+		{"Type Parameter", "T", []TypeArg{{"T", "User"}}, "User"},
+		{"Generic Type", "*Repository[T, ID]", []TypeArg{{"T", "order.Order"}, {"ID", "int"}}, "*Repository[order.Order, int]"},
+		{"Qualified Names Are Kept", "map[ID]T.T", []TypeArg{{"T", "User"}, {"ID", "string"}}, "map[string]T.T"},
+		{"Func Parameter Names Are Kept", "func(T T) error", []TypeArg{{"T", "User"}}, "func(T User) error"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tp, err := substitute(tc.tp, tc.args)
+			suite.NoError(err)
+			suite.Equal(tc.expected, tp)
+		})
+	}
+}
+
+func (suite *GenericTestSuite) TestInstantiate() {
+	entry := annotation.Entry{
+		Path: "github.com/acme/app/repo",
+		Func: annotation.Func{
+			Name:       "NewRepository",
+			Parameters: []annotation.Type{{Name: "db", Type: "*DB"}},
+			Results:    []annotation.Type{{Type: "*Repository[T, ID]"}},
+		},
+		Annotations: []annotation.Annotation{
+			{Name: "Inject", Map: map[string]interface{}{"name": "main"}},
+			{Name: "Provide", Map: map[string]interface{}{"instance": "T:Order;ID:int"}},
+			{Name: "Provide", Map: map[string]interface{}{"instance": "T:User;ID:string"}},
+		},
+	}
+
+	entries, err := instantiate(entry)
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 2)
+
+	suite.Equal("*Repository[Order, int]", entries[0].Func.Results[0].Type)
+	suite.Equal("*Repository[User, string]", entries[1].Func.Results[0].Type)
+	suite.Len(entries[0].Annotations, 2)
+	suite.Equal("github.com/acme/app/repo_NewRepository[User, string]", gid(entries[1]))
+	suite.Equal("UserString", instanceName(entries[1]))
+
+	_, err = parseInstance("Order")
+	suite.Error(err)
+}
@@ -6,16 +6,26 @@ import (
 	"github.com/americanas-go/annotation"
 	"github.com/americanas-go/errors"
 	ustrings "github.com/americanas-go/utils/strings"
+	"go/token"
 	"os"
+	"sort"
 	"strings"
 )
 
 // Graph represents a generic graph structure with vertices of any type.
 // It includes maps for vertices, incoming edges, and edges for efficient graph operations.
 type Graph[T any] struct {
-	vertices      map[string]*Vertex[T]   // Map of vertices in the graph.
-	incomingEdges map[string]int          // Map of incoming edge counts per vertex.
-	edges         map[string][]*Vertex[T] // Map of edges represented as adjacency lists.
+	vertices      map[string]*Vertex[T]          // Map of vertices in the graph.
+	incomingEdges map[string]int                 // Map of incoming edge counts per vertex.
+	edges         map[string][]*Vertex[T]        // Map of edges represented as adjacency lists.
+	incoming      map[string][]*Vertex[T]        // Map of reversed edges, the sources of the edges to each vertex.
+	attrs         map[string]map[string]EdgeAttr // Map of edge attributes per source and target vertex.
+}
+
+// EdgeAttr holds the attributes of a directed edge.
+type EdgeAttr struct {
+	Optional bool     // Whether the target vertex can be built without the source vertex.
+	Types    []string // Identities of the types the source vertex provides to the target vertex.
 }
 
 // NewGraph creates and returns a new instance of Graph.
@@ -24,6 +34,8 @@ func NewGraph[T any]() *Graph[T] {
 		vertices:      make(map[string]*Vertex[T]),
 		incomingEdges: make(map[string]int),
 		edges:         make(map[string][]*Vertex[T]),
+		incoming:      make(map[string][]*Vertex[T]),
+		attrs:         make(map[string]map[string]EdgeAttr),
 	}
 }
 
@@ -45,7 +57,15 @@ func (g *Graph[T]) AddVertex(key string, value T) {
 // AddEdge adds a directed edge from one vertex to another.
 // If either vertex does not exist, it logs a warning and does not add the edge.
 func (g *Graph[T]) AddEdge(fromKey, toKey string) {
-	_, fromExists := g.vertices[fromKey]
+	g.AddEdgeWithAttr(fromKey, toKey, EdgeAttr{})
+}
+
+// AddEdgeWithAttr adds a directed edge with the given attributes from one vertex to another.
+// If the edge already exists, it is only kept optional when both attributes are optional,
+// and it links the types of both attributes. An edge from a vertex to itself is kept, so
+// Validate reports the vertex as a cycle.
+func (g *Graph[T]) AddEdgeWithAttr(fromKey, toKey string, attr EdgeAttr) {
+	fromVertex, fromExists := g.vertices[fromKey]
 	toVertex, toExists := g.vertices[toKey]
 
 	if !fromExists {
@@ -58,35 +78,72 @@ func (g *Graph[T]) AddEdge(fromKey, toKey string) {
 		return
 	}
 
-	if fromKey == toKey {
-		log.Warnf("warning: cannot add edge to the same vertex: %v", fromKey)
-		return
-	}
-
 	for _, v := range g.edges[fromKey] {
 		if v.Key == toKey {
+			current := g.attrs[fromKey][toKey]
+			current.Optional = current.Optional && attr.Optional
+			for _, tp := range attr.Types {
+				if !ustrings.SliceContains(current.Types, tp) {
+					current.Types = append(current.Types, tp)
+				}
+			}
+			g.attrs[fromKey][toKey] = current
 			return
 		}
 	}
 
-	g.edges[fromKey] = append(g.edges[fromKey], toVertex)
+	if _, ok := g.attrs[fromKey]; !ok {
+		g.attrs[fromKey] = make(map[string]EdgeAttr)
+	}
+
+	// adjacency lists are kept sorted by key, so traversals are stable
+	g.edges[fromKey] = insertVertex(g.edges[fromKey], toVertex)
+	g.incoming[toKey] = insertVertex(g.incoming[toKey], fromVertex)
+	g.attrs[fromKey][toKey] = attr
 	g.incomingEdges[toKey]++
 	log.Debugf("edge added from %v to %v", fromKey, toKey)
 }
 
-// VerticesWithNoIncomingEdges returns a list of vertices with no incoming edges.
+// insertVertex inserts the vertex into a list of vertices sorted by key.
+func insertVertex[T any](vertices []*Vertex[T], vertex *Vertex[T]) []*Vertex[T] {
+	i := sort.Search(len(vertices), func(i int) bool { return vertices[i].Key >= vertex.Key })
+	vertices = append(vertices, nil)
+	copy(vertices[i+1:], vertices[i:])
+	vertices[i] = vertex
+
+	return vertices
+}
+
+// InDegree returns the number of edges to the vertex.
+func (g *Graph[T]) InDegree(key string) int {
+	return g.incomingEdges[key]
+}
+
+// OutDegree returns the number of edges from the vertex.
+func (g *Graph[T]) OutDegree(key string) int {
+	return len(g.edges[key])
+}
+
+// EdgeAttr returns the attributes of the edge from one vertex to another.
+// It returns the zero value if the edge does not exist.
+func (g *Graph[T]) EdgeAttr(fromKey, toKey string) EdgeAttr {
+	return g.attrs[fromKey][toKey]
+}
+
+// VerticesWithNoIncomingEdges returns a list of vertices with no incoming edges, sorted by key.
 func (g *Graph[T]) VerticesWithNoIncomingEdges() []*Vertex[T] {
 	var vertices []*Vertex[T]
-	for key, count := range g.incomingEdges {
-		if count == 0 {
+	for _, key := range g.sortedKeys() {
+		if g.incomingEdges[key] == 0 {
 			vertices = append(vertices, g.vertices[key])
 		}
 	}
 	return vertices
 }
 
+// Print logs the vertices in topological order, along with their edges.
 func (g *Graph[T]) Print() {
-	for _, vertex := range g.vertices {
+	for _, vertex := range g.orderedVertices() {
 		log.Infof("%v (%v) -> ", vertex.Key, vertex.Value)
 		for _, edge := range g.edges[vertex.Key] {
 			log.Infof("%v ", edge.Value)
@@ -106,14 +163,27 @@ func (g *Graph[T]) ExportToGraphviz(filename string) error {
 		return err
 	}
 
-	for key, _ := range g.vertices {
-		_, err = file.WriteString(fmt.Sprintf("\t\"%s\" [label=\"%s\"];\n", key, key))
+	for _, vertex := range g.orderedVertices() {
+		key := vertex.Key
+
+		// external components are drawn apart from the generated ones
+		shape := ""
+		if c, ok := any(vertex.Value).(Component); ok && c.External {
+			shape = ", shape=box, style=dashed"
+		}
+
+		_, err = file.WriteString(fmt.Sprintf("\t\"%s\" [label=\"%s\"%s];\n", key, key, shape))
 		if err != nil {
 			return err
 		}
 
 		for _, edge := range g.edges[key] {
-			_, err = file.WriteString(fmt.Sprintf("\t\"%s\" -> \"%s\";\n", key, edge.Key))
+			style := ""
+			if g.attrs[key][edge.Key].Optional {
+				style = " [style=dashed]"
+			}
+
+			_, err = file.WriteString(fmt.Sprintf("\t\"%s\" -> \"%s\"%s;\n", key, edge.Key, style))
 			if err != nil {
 				return err
 			}
@@ -129,17 +199,219 @@ func (g *Graph[T]) ExportToGraphviz(filename string) error {
 }
 
 type Component struct {
-	Entry annotation.Entry
-	An    Annotation
+	Entry     annotation.Entry
+	An        Annotation
+	Provides  []string          // identities provided by the component, one or more per result.
+	Module    *Module           // named module bundling the component, if any.
+	Lifecycle bool              // whether the component needs the fx.Lifecycle.
+	External  bool              // whether the component is provided outside of the scanned code.
+	Types     map[string]string // types referenced by the generated module, as written, qualified by their import paths.
+}
+
+// GraphOption configures how the graph is built from the entries.
+type GraphOption func(*graphOptions)
+
+type graphOptions struct {
+	profile   string
+	resolver  TypeResolver
+	declared  []Object
+	objects   map[string]Object
+	externals []External
+}
+
+// WithObjects sets the parameter and result objects whose fields are expanded
+// into dependencies and provisions of their own.
+func WithObjects(objects []Object) GraphOption {
+	return func(o *graphOptions) {
+		o.declared = append(o.declared, objects...)
+	}
+}
+
+// WithResolver sets the resolver of the type identities. By default, types are
+// qualified by the name of their package.
+func WithResolver(resolver TypeResolver) GraphOption {
+	return func(o *graphOptions) {
+		o.resolver = resolver
+	}
+}
+
+// WithProfile builds the graph of a profile, made of the components bound to
+// the profile along with the ones bound to none. By default, only the
+// components bound to no profile are taken.
+func WithProfile(profile string) GraphOption {
+	return func(o *graphOptions) {
+		o.profile = profile
+	}
+}
+
+func newGraphOptions(entries []annotation.Entry, opts ...GraphOption) (*graphOptions, error) {
+	options := &graphOptions{
+		resolver:  newNameResolver(entries),
+		objects:   make(map[string]Object),
+		externals: append([]External{}, Builtins...),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	for _, object := range options.declared {
+		key, err := options.resolver.Resolve(object.Path, object.Package, object.File, object.Name)
+		if err != nil {
+			return nil, err
+		}
+		options.objects[key] = object
+	}
+
+	return options, nil
+}
+
+// id returns the identity of a type as written in the entry, qualified by the
+// annotation.
+func (o *graphOptions) id(entry annotation.Entry, tp string, ann Annotation) (string, error) {
+	resolved, err := o.resolver.Resolve(entry.Path, entry.Package, entry.File, tp)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{resolved, ann.ID()}, "_"), nil
+}
+
+// object returns the parameter or result object of a type as written in the
+// entry, if any.
+func (o *graphOptions) object(entry annotation.Entry, tp string) (Object, bool) {
+	if len(o.objects) == 0 {
+		return Object{}, false
+	}
+
+	resolved, err := o.resolver.Resolve(entry.Path, entry.Package, entry.File, tp)
+	if err != nil {
+		return Object{}, false
+	}
+
+	object, ok := o.objects[resolved]
+	return object, ok
+}
+
+// qualify resolves the types referenced by the generated module of the entry
+// to the import paths of their packages, keyed as written: the type arguments
+// of its instance, the interfaces its results are bound to, the type its value
+// is converted to and the type bound to its hook.
+func (o *graphOptions) qualify(entry annotation.Entry) (map[string]string, error) {
+	var tps []string
+	for _, arg := range typeArgs(entry) {
+		tps = append(tps, arg.Type)
+	}
+
+	for _, ann := range entry.Annotations {
+		switch strings.ToUpper(ann.Name) {
+		case AnnotationTypePROVIDE.String():
+			a, err := decodeAnnotation(entry, ann)
+			if err != nil {
+				return nil, err
+			}
+			if a.As != "" {
+				tps = append(tps, a.As)
+			}
+		case AnnotationTypeSUPPLY.String():
+			a := Annotation{}
+			err := ann.Decode(&a)
+			if err != nil {
+				return nil, err
+			}
+			if a.Type != "" {
+				tps = append(tps, a.Type)
+			}
+		}
+	}
+
+	hook, err := getHook(entry)
+	if err != nil {
+		return nil, err
+	}
+	if hook != nil {
+		tps = append(tps, hook.Type)
+	}
+
+	qualified := make(map[string]string)
+	for _, tp := range tps {
+		q, err := o.resolver.Qualify(entry.Path, entry.Package, entry.File, tp)
+		if err != nil {
+			return nil, err
+		}
+		qualified[tp] = q
+	}
+
+	return qualified, nil
 }
 
-func NewGraphFromEntries(ctx context.Context, entries []annotation.Entry) (*Graph[Component], error) {
+// external returns the component of the external providing the identity, if any.
+func (o *graphOptions) external(id string) (Component, bool) {
+	for _, external := range o.externals {
+		if ustrings.SliceContains(external.ids(), id) {
+			return external.component(), true
+		}
+	}
+
+	return Component{}, false
+}
 
-	out := make(map[string]Component)
+// fieldID returns the identity of a field of the object, stripping the slice
+// of groups.
+func (o *graphOptions) fieldID(object Object, field Field) (string, error) {
+	tp := field.Type
+	if field.An.Group != "" && (field.An.Flatten || !object.Out) {
+		tp = strings.TrimPrefix(tp, "[]")
+	}
+
+	resolved, err := o.resolver.Resolve(object.Path, object.Package, object.File, tp)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{resolved, field.An.ID()}, "_"), nil
+}
+
+func NewGraphFromEntries(ctx context.Context, entries []annotation.Entry, opts ...GraphOption) (*Graph[Component], error) {
+
+	options, err := newGraphOptions(entries, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]Component)
 	in := make(map[string][]Component)
+	provides := make(map[string][]string)
+	decorators := make(map[string]Component)
+	primaries := make(map[string][]Component)
+	bound := make(map[string]string)
+	var hooks []Component
+	var invokes []Component
 
-	for _, entry := range entries {
+	var modules []*Module
+
+	// generic functions join the graph once per instance
+	var instances []annotation.Entry
+	for _, entry := range inProfile(entries, options.profile) {
 		if !entry.IsFunc() {
+			instances = append(instances, entry)
+			continue
+		}
+
+		expanded, err := instantiate(entry)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, expanded...)
+	}
+
+	for _, entry := range instances {
+		if !entry.IsFunc() {
+			// packages and types may only anchor modules
+			anchors, err := newModules(entry)
+			if err != nil {
+				return nil, err
+			}
+			modules = append(modules, anchors...)
 			continue
 		}
 
@@ -148,6 +420,74 @@ func NewGraphFromEntries(ctx context.Context, entries []annotation.Entry) (*Grap
 			continue
 		}
 
+		// the receiver of an annotated method is an implicit dependency
+		if entry.Struct != "" {
+			id, err := options.id(entry, entry.Struct, Annotation{})
+			if err != nil {
+				return nil, err
+			}
+			in[id] = append(in[id], Component{
+				Entry: entry,
+			})
+		}
+
+		// the fields of parameter objects are dependencies on their own
+		for _, param := range entry.Func.Parameters {
+			object, ok := options.object(entry, param.Type)
+			if !ok || object.Out {
+				continue
+			}
+
+			for _, field := range object.Fields {
+				id, err := options.fieldID(object, field)
+				if err != nil {
+					return nil, err
+				}
+				an := field.An
+				an.Param = param.Name + "." + field.Name
+				in[id] = append(in[id], Component{
+					Entry: entry,
+					An:    an,
+				})
+			}
+		}
+
+		// fx fills every parameter of constructors and invokes, so the ones
+		// without an inject are dependencies by their default identity
+		if hasAnnotation(entry, AnnotationTypePROVIDE) || hasAnnotation(entry, AnnotationTypeINVOKE) {
+			for i, param := range entry.Func.Parameters {
+				_, injected, err := injectAt(entry, i)
+				if err != nil {
+					return nil, err
+				}
+				if injected {
+					continue
+				}
+
+				// variadic parameters are left empty by fx
+				if strings.HasPrefix(param.Type, "...") {
+					continue
+				}
+
+				if _, ok := options.object(entry, param.Type); ok {
+					continue
+				}
+
+				id, err := options.id(entry, param.Type, Annotation{})
+				if err != nil {
+					return nil, err
+				}
+
+				index := i
+				in[id] = append(in[id], Component{
+					Entry: entry,
+					An:    Annotation{Index: &index, Param: param.Name},
+				})
+			}
+		}
+
+		provided := make(map[int]struct{})
+
 		for _, ann := range entry.Annotations {
 			if !isValidAnnotation(ann.Name) {
 				log.Warnf("the annotation %s is invalid", ann.Name)
@@ -156,9 +496,7 @@ func NewGraphFromEntries(ctx context.Context, entries []annotation.Entry) (*Grap
 
 			annType, _ := ParseAnnotationType(strings.ToUpper(ann.Name))
 
-			a := Annotation{}
-
-			err := ann.Decode(&a)
+			a, err := decodeAnnotation(entry, ann)
 			if err != nil {
 				return nil, err
 			}
@@ -166,30 +504,120 @@ func NewGraphFromEntries(ctx context.Context, entries []annotation.Entry) (*Grap
 			switch annType {
 			case AnnotationTypePROVIDE:
 
-				if a.Index == nil {
-					return nil, errors.NotValidf("the index parameter is required on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				if a.Optional {
+					return nil, errors.NotValidf("the optional parameter is only allowed on inject, found on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				if a.Soft {
+					return nil, errors.NotValidf("the soft parameter is only allowed on inject, found on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				if a.Primary && a.Group != "" {
+					return nil, errors.NotValidf("the primary parameter cannot be combined with group on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				if a.Self && a.As == "" {
+					return nil, errors.NotValidf("the self parameter requires the as parameter on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				// fx.Self applies to every result, so it can't be restricted to one of many
+				if a.Self && len(values(entry)) > 1 {
+					return nil, errors.NotValidf("the self parameter is only allowed on functions with a single result, found on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
 				}
 
 				index := *a.Index
 
+				if index == len(entry.Func.Results)-1 && hasErrorResult(entry) {
+					return nil, errors.NotValidf("the result %d is the error result and cannot be provided, found on the annotation %s in the entry %s.%s", index, ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				if _, ok := provided[index]; ok {
+					return nil, errors.NotValidf("the result %d is provided more than once, found on the annotation %s in the entry %s.%s", index, ann.Name, entry.Path, entry.Func.Name)
+				}
+				provided[index] = struct{}{}
+
 				for i, res := range entry.Func.Results {
 					if index != i {
 						continue
 					}
 
-					id := xid(entry.Package, res.Type, a)
-					if _, ok := out[id]; !ok {
-						out[id] = Component{
+					// the fields of result objects are provisions on their own
+					if object, ok := options.object(entry, res.Type); ok && object.Out {
+						if a.Name != "" || a.Group != "" || a.As != "" {
+							return nil, errors.NotValidf("the result object %s is qualified by the tags of its fields, found on the annotation %s in the entry %s.%s", res.Type, ann.Name, entry.Path, entry.Func.Name)
+						}
+
+						for _, field := range object.Fields {
+							id, err := options.fieldID(object, field)
+							if err != nil {
+								return nil, err
+							}
+							provides[gid(entry)] = append(provides[gid(entry)], id)
+							component := Component{
+								Entry: entry,
+								An:    field.An,
+							}
+
+							out[id] = append(out[id], component)
+							if a.Primary || hasAnnotation(entry, AnnotationTypePRIMARY) {
+								primaries[id] = append(primaries[id], component)
+							}
+						}
+						continue
+					}
+
+					tp := res.Type
+					if a.Group != "" && a.Flatten {
+						if !strings.HasPrefix(tp, "[]") {
+							return nil, errors.NotValidf("the flatten parameter requires a slice result on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+						}
+						tp = strings.TrimPrefix(tp, "[]")
+					}
+
+					// a result bound to an interface is registered under the interface
+					// identity, and also under its own when self is set
+					tps := []string{tp}
+					if a.As != "" {
+						tps = []string{a.As}
+						if a.Self {
+							tps = append(tps, tp)
+						}
+					}
+
+					for _, tp := range tps {
+						id, err := options.id(entry, tp, a)
+						if err != nil {
+							return nil, err
+						}
+						provides[gid(entry)] = append(provides[gid(entry)], id)
+						component := Component{
 							Entry: entry,
 							An:    a,
 						}
+
+						out[id] = append(out[id], component)
+						if a.Primary || hasAnnotation(entry, AnnotationTypePRIMARY) {
+							primaries[id] = append(primaries[id], component)
+						}
 					}
 				}
 
 			case AnnotationTypeINJECT:
 
-				if a.Index == nil {
-					return nil, errors.NotValidf("the index parameter is required on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				if a.Profile != "" {
+					return nil, errors.NotValidf("the profile parameter is not allowed on inject, found on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				if a.Private {
+					return nil, errors.NotValidf("the private parameter is only allowed on provide, found on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				if a.Flatten {
+					return nil, errors.NotValidf("the flatten parameter is only allowed on provide, found on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				if a.Optional && a.Group != "" {
+					return nil, errors.NotValidf("the optional parameter cannot be combined with group on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
 				}
 
 				index := *a.Index
@@ -199,68 +627,486 @@ func NewGraphFromEntries(ctx context.Context, entries []annotation.Entry) (*Grap
 						continue
 					}
 
-					id := xid(entry.Package, param.Type, a)
+					// parameter objects are already expanded into their fields
+					if _, ok := options.object(entry, param.Type); ok {
+						if a.Name != "" || a.Group != "" || a.Optional {
+							return nil, errors.NotValidf("the parameter object %s is qualified by the tags of its fields, found on the annotation %s in the entry %s.%s", param.Type, ann.Name, entry.Path, entry.Func.Name)
+						}
+						continue
+					}
+
+					tp := param.Type
+					if a.Group != "" {
+						if !strings.HasPrefix(tp, "[]") {
+							return nil, errors.NotValidf("the group parameter requires a slice parameter on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+						}
+						tp = strings.TrimPrefix(tp, "[]")
+					}
+
+					id, err := options.id(entry, tp, a)
+					if err != nil {
+						return nil, err
+					}
 
-					if _, ok := out[id]; !ok {
+					if _, ok := in[id]; !ok {
 						in[id] = make([]Component, 0)
 					}
+					a.Param = param.Name
 					in[id] = append(in[id], Component{
 						Entry: entry,
 						An:    a,
 					})
 				}
 
+			case AnnotationTypeDECORATE:
+
+				index := *a.Index
+				if index < 0 || index >= len(values(entry)) {
+					return nil, errors.NotValidf("the result %d cannot be decorated, found on the annotation %s in the entry %s.%s", index, ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				param := decoratedParam(entry, index)
+				if param < 0 {
+					return nil, errors.NotValidf("the decorator must take the type %s it decorates, found on the annotation %s in the entry %s.%s", entry.Func.Results[index].Type, ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				tp := entry.Func.Results[index].Type
+				if a.Group != "" {
+					if !strings.HasPrefix(tp, "[]") {
+						return nil, errors.NotValidf("the group parameter requires a slice result on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+					}
+					tp = strings.TrimPrefix(tp, "[]")
+				}
+
+				id, err := options.id(entry, tp, a)
+				if err != nil {
+					return nil, err
+				}
+				component := Component{
+					Entry: entry,
+					An:    a,
+				}
+
+				if other, ok := decorators[id]; ok {
+					return nil, errors.NotValidf("the type %s is decorated by both %s and %s", id, gid(other.Entry), gid(entry))
+				}
+				decorators[id] = component
+
+				// the decorated value is an implicit dependency of its decorator
+				in[id] = append(in[id], component)
+
+			case AnnotationTypeSUPPLY:
+
+				if a.Flatten || a.Soft || a.Optional || a.Private || a.As != "" {
+					return nil, errors.NotValidf("only the name, group and type parameters are allowed on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				if !token.IsExported(entry.Func.Name) {
+					return nil, errors.NotValidf("the supplied value must be exported, found on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				tp := a.Type
+				if tp == "" && len(entry.Func.Results) > 0 {
+					tp = entry.Func.Results[0].Type
+				}
+
+				if tp == "" {
+					return nil, errors.NotValidf("the type parameter is required for untyped values on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+				id, err := options.id(entry, tp, a)
+				if err != nil {
+					return nil, err
+				}
+				provides[gid(entry)] = append(provides[gid(entry)], id)
+				component := Component{
+					Entry: entry,
+					An:    a,
+				}
+
+				out[id] = append(out[id], component)
+				if a.Primary || hasAnnotation(entry, AnnotationTypePRIMARY) {
+					primaries[id] = append(primaries[id], component)
+				}
+
+			case AnnotationTypeONSTART, AnnotationTypeONSTOP:
+
+				hook, err := newHook(entry, annType)
+				if err != nil {
+					return nil, err
+				}
+
+				component := Component{
+					Entry:     entry,
+					An:        a,
+					Lifecycle: true,
+				}
+
+				// the receiver is already an implicit dependency of methods
+				id, err := options.id(entry, hook.Type, Annotation{})
+				if err != nil {
+					return nil, err
+				}
+				if hook.Index >= 0 {
+					inject, injected, err := injectAt(entry, hook.Index)
+					if err != nil {
+						return nil, err
+					}
+
+					id, err = options.id(entry, hook.Type, inject)
+					if err != nil {
+						return nil, err
+					}
+					if !injected {
+						in[id] = append(in[id], component)
+					}
+				}
+
+				hooks = append(hooks, component)
+				bound[gid(entry)] = id
+
+			case AnnotationTypePRIVATE:
+
+				if !hasAnnotation(entry, AnnotationTypePROVIDE) {
+					return nil, errors.NotValidf("the annotation %s requires a provide annotation in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+			case AnnotationTypePRIMARY:
+
+				if !hasAnnotation(entry, AnnotationTypePROVIDE) && !hasAnnotation(entry, AnnotationTypeSUPPLY) {
+					return nil, errors.NotValidf("the annotation %s requires a provide or supply annotation in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+				}
+
+			case AnnotationTypeREPLACE:
+				return nil, errors.NotValidf("the annotation %s is only allowed in test files, found in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+
 			case AnnotationTypeINVOKE:
+				invokes = append(invokes, Component{
+					Entry: entry,
+					An:    a,
+				})
 			case AnnotationTypeMODULE:
+				module, err := newModule(entry, a)
+				if err != nil {
+					return nil, err
+				}
+				modules = append(modules, module)
 			}
 		}
 
 	}
 
+	err = selectPrimaries(out, in, primaries, provides)
+	if err != nil {
+		return nil, err
+	}
+
+	// identities are visited in order, so the annotation kept for a component
+	// providing several of them does not depend on the order of the maps
+	var ids []string
+	for id := range out {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
 	graph := NewGraph[Component]()
-	for id, ae := range out {
-		graph.AddVertex(id, ae)
+	for _, id := range ids {
+		for _, ae := range out[id] {
+			if _, ok := graph.vertices[gid(ae.Entry)]; !ok {
+				ae.Provides = provides[gid(ae.Entry)]
+				graph.AddVertex(gid(ae.Entry), ae)
+			}
+		}
 	}
 
-	for id, aes := range in {
+	for _, ae := range invokes {
+		if _, ok := graph.vertices[gid(ae.Entry)]; !ok {
+			graph.AddVertex(gid(ae.Entry), ae)
+		}
+	}
 
-		if outAnnoEntry, ok := out[id]; ok {
-			for _, inb := range aes {
+	for _, ae := range hooks {
+		if _, ok := graph.vertices[gid(ae.Entry)]; !ok {
+			graph.AddVertex(gid(ae.Entry), ae)
+		}
+	}
+
+	for _, ae := range decorators {
+		if _, ok := graph.vertices[gid(ae.Entry)]; !ok {
+			graph.AddVertex(gid(ae.Entry), ae)
+		}
+	}
+
+	var consumed []string
+	for id := range in {
+		consumed = append(consumed, id)
+	}
+	sort.Strings(consumed)
+
+	var missing []string
+	for _, id := range consumed {
+		aes := in[id]
+
+		outAnnoEntries, ok := out[id]
+		decorator, decorated := decorators[id]
+
+		// types provided outside of the scanned code join as external vertices
+		if !ok {
+			if external, found := options.external(id); found {
+				if _, exists := graph.vertices[gid(external.Entry)]; !exists {
+					graph.AddVertex(gid(external.Entry), external)
+				}
+				outAnnoEntries, ok = []Component{external}, true
+			}
+		}
+
+		// a value group may have zero or more providers
+		for _, inb := range aes {
+
+			if !ok && inb.An.Group == "" && !inb.An.Optional {
+				missing = append(missing, fmt.Sprintf("%s required by %s", id, dependent(inb)))
+				continue
+			}
 
+			if _, ok := graph.vertices[gid(inb.Entry)]; !ok {
 				graph.AddVertex(gid(inb.Entry), inb)
-				graph.AddEdge(gid(outAnnoEntry.Entry), gid(inb.Entry))
+			}
+
+			// a decorator sits between the providers and the other consumers
+			from := outAnnoEntries
+			if decorated && gid(inb.Entry) != gid(decorator.Entry) {
+				from = []Component{decorator}
+			}
 
+			for _, outAnnoEntry := range from {
+				graph.AddEdgeWithAttr(gid(outAnnoEntry.Entry), gid(inb.Entry), EdgeAttr{Optional: inb.An.Optional, Types: []string{id}})
 			}
-		} else {
-			return nil, errors.NotFoundf("provider not found for %s", id)
+
 		}
 
 	}
 
+	// every unresolved dependency is reported at once
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, errors.NotFoundf("provider not found for %s", strings.Join(missing, "; "))
+	}
+
+	err = graph.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// the generated modules reference the types by the import paths resolved here
+	for _, key := range graph.sortedKeys() {
+		vertex := graph.vertices[key]
+		if vertex.Value.External {
+			continue
+		}
+
+		vertex.Value.Types, err = options.qualify(vertex.Value.Entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// hooks are bound to the component providing their type
+	providers := make(map[string]string)
+	for key, id := range bound {
+		if aes, ok := out[id]; ok {
+			providers[key] = gid(aes[0].Entry)
+		}
+	}
+	orderHooks(graph, hooks, providers)
+
+	err = validateModules(modules)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateModuleNames(graph, modules)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range graph.sortedKeys() {
+		vertex := graph.vertices[key]
+		if vertex.Value.External {
+			continue
+		}
+
+		module, err := moduleOf(modules, vertex.Value.Entry)
+		if err != nil {
+			return nil, err
+		}
+		vertex.Value.Module = module
+	}
+
+	err = validateVisibility(graph)
+	if err != nil {
+		return nil, err
+	}
+
 	return graph, nil
 }
 
 func gid(entry annotation.Entry) string {
-	return strings.Join([]string{entry.Path, entry.Func.Name}, "_")
+	if entry.Struct != "" {
+		return strings.Join([]string{entry.Path, strings.TrimPrefix(entry.Struct, "*"), entry.Func.Name}, "_")
+	}
+
+	name := entry.Func.Name
+	if args := typeArgs(entry); len(args) > 0 {
+		var tps []string
+		for _, arg := range args {
+			tps = append(tps, arg.Type)
+		}
+		name += "[" + strings.Join(tps, ", ") + "]"
+	}
+
+	return strings.Join([]string{entry.Path, name}, "_")
 }
 
-func xid(pkg string, tp string, ann Annotation) string {
+// selectPrimaries keeps a single provider for each identity provided more
+// than once outside of a group, the one marked as primary. Otherwise, which
+// provider wins would depend on the order the entries were collected. The
+// dependencies of the losing providers are dropped along with them.
+func selectPrimaries(out map[string][]Component, in map[string][]Component, primaries map[string][]Component, provides map[string][]string) error {
+	losers := make(map[string]struct{})
 
-	ref := false
-	if strings.Contains(tp, "*") {
-		ref = true
+	var ids []string
+	for id, aes := range out {
+		if len(aes) > 1 && aes[0].An.Group == "" {
+			ids = append(ids, id)
+		}
 	}
+	sort.Strings(ids)
 
-	tp = strings.ReplaceAll(tp, "*", "")
-	if !strings.Contains(tp, ".") {
-		tp = strings.Join([]string{pkg, ".", tp}, "")
+	for _, id := range ids {
+		switch len(primaries[id]) {
+		case 0:
+			return errors.Conflictf("the type %s is provided by %s, mark one of them as primary", id, candidates(out[id]))
+		case 1:
+		default:
+			return errors.Conflictf("the type %s has more than one primary provider, %s", id, candidates(primaries[id]))
+		}
+
+		primary := primaries[id][0]
+		for _, ae := range out[id] {
+			if gid(ae.Entry) == gid(primary.Entry) {
+				continue
+			}
+
+			// fx provides every result of a constructor, so the losing one can't stay
+			var kept []string
+			for _, provided := range provides[gid(ae.Entry)] {
+				if provided != id {
+					kept = append(kept, provided)
+				}
+			}
+			if len(kept) > 0 {
+				return errors.Conflictf("the type %s is provided by the primary %s, but also by %s, which provides other types", id, candidates([]Component{primary}), candidates([]Component{ae}))
+			}
+			delete(provides, gid(ae.Entry))
+			losers[gid(ae.Entry)] = struct{}{}
+		}
+
+		out[id] = []Component{primary}
+	}
+
+	for id, aes := range in {
+		var kept []Component
+		for _, ae := range aes {
+			if _, ok := losers[gid(ae.Entry)]; !ok {
+				kept = append(kept, ae)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(in, id)
+			continue
+		}
+		in[id] = kept
+	}
+
+	return nil
+}
+
+// dependent describes the function and parameter depending on a type.
+func dependent(inb Component) string {
+	name := inb.Entry.Func.Name
+	if inb.Entry.Struct != "" {
+		name = strings.TrimPrefix(inb.Entry.Struct, "*") + "." + name
 	}
 
-	if ref {
-		tp = strings.Join([]string{"*", tp}, "")
+	switch {
+	case inb.An.Param != "":
+		return fmt.Sprintf("the parameter %s of %s.%s", inb.An.Param, inb.Entry.Path, name)
+	case inb.An.Index != nil:
+		return fmt.Sprintf("the parameter %d of %s.%s", *inb.An.Index, inb.Entry.Path, name)
+	case inb.Entry.Struct != "":
+		return fmt.Sprintf("the receiver of %s.%s", inb.Entry.Path, name)
+	default:
+		return fmt.Sprintf("%s.%s", inb.Entry.Path, name)
 	}
+}
 
-	return strings.Join([]string{tp, ann.ID()}, "_")
+// candidates describes the providers of a type by their function and file.
+func candidates(aes []Component) string {
+	var descs []string
+	for _, ae := range aes {
+		name := ae.Entry.Func.Name
+		if ae.Entry.Struct != "" {
+			name = strings.TrimPrefix(ae.Entry.Struct, "*") + "." + name
+		}
+		descs = append(descs, fmt.Sprintf("%s.%s in %s.go", ae.Entry.Path, name, ae.Entry.File))
+	}
+	sort.Strings(descs)
+
+	return strings.Join(descs, ", ")
+}
+
+// hasAnnotation reports whether the entry has an annotation of the given type.
+func hasAnnotation(entry annotation.Entry, annType AnnotationType) bool {
+	for _, ann := range entry.Annotations {
+		if strings.ToUpper(ann.Name) == annType.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasErrorResult reports whether the last result of the entry function is an error.
+func hasErrorResult(entry annotation.Entry) bool {
+	results := entry.Func.Results
+	return len(results) > 0 && results[len(results)-1].Type == "error"
+}
+
+// values returns the results of the entry function without its trailing error.
+func values(entry annotation.Entry) []string {
+	var tps []string
+	for _, res := range entry.Func.Results {
+		tps = append(tps, res.Type)
+	}
+
+	if hasErrorResult(entry) {
+		tps = tps[:len(tps)-1]
+	}
+
+	return tps
+}
+
+// decoratedParam returns the index of the parameter taking the value decorated
+// by the given result of the entry function, or -1 if there is none.
+func decoratedParam(entry annotation.Entry, result int) int {
+	for i, param := range entry.Func.Parameters {
+		if param.Type == entry.Func.Results[result].Type {
+			return i
+		}
+	}
+
+	return -1
 }
 
 func isValidCombinedAnnotations(annons []annotation.Annotation) bool {
@@ -269,7 +1115,26 @@ func isValidCombinedAnnotations(annons []annotation.Annotation) bool {
 		all = append(all, strings.ToUpper(ann.Name))
 	}
 
-	return !ustrings.SliceContainsAll(all, []string{AnnotationTypePROVIDE.String(), AnnotationTypeINJECT.String()})
+	// an entry is either a provider, an invoke, a decorator, a value or a hook
+	exclusive := 0
+	for _, annType := range []AnnotationType{
+		AnnotationTypePROVIDE,
+		AnnotationTypeINVOKE,
+		AnnotationTypeDECORATE,
+		AnnotationTypeSUPPLY,
+		AnnotationTypeONSTART,
+		AnnotationTypeONSTOP,
+	} {
+		if ustrings.SliceContains(all, annType.String()) {
+			exclusive++
+		}
+	}
+
+	if exclusive > 1 {
+		return false
+	}
+
+	return !(ustrings.SliceContains(all, AnnotationTypeSUPPLY.String()) && ustrings.SliceContains(all, AnnotationTypeINJECT.String()))
 }
 
 func isValidAnnotation(value string) bool {
@@ -277,7 +1142,14 @@ func isValidAnnotation(value string) bool {
 		AnnotationTypeMODULE.String(),
 		AnnotationTypePROVIDE.String(),
 		AnnotationTypeINJECT.String(),
-		AnnotationTypeINVOKE.String()},
+		AnnotationTypeINVOKE.String(),
+		AnnotationTypeDECORATE.String(),
+		AnnotationTypeSUPPLY.String(),
+		AnnotationTypeONSTART.String(),
+		AnnotationTypeONSTOP.String(),
+		AnnotationTypePRIVATE.String(),
+		AnnotationTypeREPLACE.String(),
+		AnnotationTypePRIMARY.String()},
 		strings.ToUpper(value)) {
 		return true
 	}
@@ -69,6 +69,64 @@ func (suite *GraphTestSuite) TestAddEdge() {
 	}
 }
 
+func (suite *GraphTestSuite) TestAddEdgeWithAttr() {
+	testCases := []struct {
+		name     string
+		attrs    []EdgeAttr
+		expected EdgeAttr
+	}{
+		{"Optional Edge", []EdgeAttr{{Optional: true}}, EdgeAttr{Optional: true}},
+		{"Required Edge Added Twice", []EdgeAttr{{}, {Optional: true}}, EdgeAttr{}},
+		{"Optional Edge Added Twice", []EdgeAttr{{Optional: true}, {Optional: true}}, EdgeAttr{Optional: true}},
+		{"Types Merged", []EdgeAttr{{Types: []string{"a"}}, {Types: []string{"a", "b"}}}, EdgeAttr{Types: []string{"a", "b"}}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			g := NewGraph[string]()
+			g.AddVertex("vertex1", "value1")
+			g.AddVertex("vertex2", "value2")
+			for _, attr := range tc.attrs {
+				g.AddEdgeWithAttr("vertex1", "vertex2", attr)
+			}
+
+			suite.Len(g.edges["vertex1"], 1)
+			suite.Equal(1, g.incomingEdges["vertex2"])
+			suite.Equal(tc.expected, g.EdgeAttr("vertex1", "vertex2"))
+		})
+	}
+}
+
+func (suite *GraphTestSuite) TestDegree() {
+	g := NewGraph[string]()
+	for _, key := range []string{"a", "b", "c"} {
+		g.AddVertex(key, key)
+	}
+	g.AddEdge("a", "b")
+	g.AddEdge("a", "c")
+	g.AddEdge("b", "c")
+	g.AddEdge("b", "c")
+
+	testCases := []struct {
+		name string
+		key  string
+		in   int
+		out  int
+	}{
+		{"Source", "a", 0, 2},
+		{"Middle", "b", 1, 1},
+		{"Sink Linked Twice By The Same Vertex", "c", 2, 0},
+		{"Missing Vertex", "d", 0, 0},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.Equal(tc.in, g.InDegree(tc.key))
+			suite.Equal(tc.out, g.OutDegree(tc.key))
+		})
+	}
+}
+
 func (suite *GraphTestSuite) TestVerticesWithNoIncomingEdges() {
 	testCases := []struct {
 		name       string
@@ -114,13 +172,35 @@ func (suite *GraphTestSuite) TestVerticesWithNoIncomingEdges() {
 	}
 }
 
+func (suite *GraphTestSuite) TestIsValidCombinedAnnotations() {
+	testCases := []struct {
+		name     string
+		annons   []string
+		expected bool
+	}{
+		{"Provide With Inject", []string{"Provide", "Inject"}, true},
+		{"Invoke With Inject", []string{"Invoke", "Inject"}, true},
+		{"Provide With Invoke", []string{"Provide", "Invoke"}, false},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			var annons []annotation.Annotation
+			for _, name := range tc.annons {
+				annons = append(annons, annotation.Annotation{Name: name})
+			}
+			suite.Equal(tc.expected, isValidCombinedAnnotations(annons))
+		})
+	}
+}
+
 type NewGraphFromEntriesTestSuite struct {
 	suite.Suite
 	testData map[string][]annotation.Entry
 }
 
 func TestNewGraphFromEntriesTestSuite(t *testing.T) {
-	suite.Run(t, new(GraphTestSuite))
+	suite.Run(t, new(NewGraphFromEntriesTestSuite))
 }
 
 func (suite *NewGraphFromEntriesTestSuite) SetupSuite() {
@@ -155,6 +235,28 @@ func (suite *NewGraphFromEntriesTestSuite) loadEntriesFromYAML(filename string)
 	return entries, nil
 }
 
+func (suite *NewGraphFromEntriesTestSuite) TestVertexKeys() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["1_success.yaml"])
+	suite.Require().NoError(err)
+
+	// vertices are keyed by component, so providers, consumers and invokes
+	// each have one vertex
+	var keys []string
+	for key := range graph.vertices {
+		keys = append(keys, key)
+	}
+	suite.ElementsMatch([]string{
+		"github.com/jpfaria/tests/annotated_LorenMethod",
+		"github.com/americanas-go/inject/examples/simple_Foo",
+		"github.com/americanas-go/inject/examples/simple_FooBar",
+		"github.com/americanas-go/inject/examples/simple_FooBaz",
+		"github.com/jpfaria/tests/annotated_NewContext",
+		"github.com/jpfaria/tests/annotated_NewRequest",
+		"github.com/americanas-go/inject/examples/simple_Bar",
+		"github.com/americanas-go/inject/examples/simple_Foz",
+	}, keys)
+}
+
 func (suite *NewGraphFromEntriesTestSuite) TestNewGraphFromEntries() {
 	testCases := []struct {
 		name      string
@@ -164,7 +266,7 @@ func (suite *NewGraphFromEntriesTestSuite) TestNewGraphFromEntries() {
 	}{
 		{
 			name:      "valid",
-			id:        "1_valid.yaml",
+			id:        "1_success.yaml",
 			expectErr: false,
 		},
 		{
@@ -174,17 +276,205 @@ func (suite *NewGraphFromEntriesTestSuite) TestNewGraphFromEntries() {
 			errType:   errors.NotFoundf("tipo de erro esperado"),
 		},
 		{
-			name:      "provide index not found",
+			name:      "provider of the parameter not found",
 			id:        "3_provide_index_notfound.yaml",
 			expectErr: true,
-			errType:   errors.NotValidf("tipo de erro esperado"),
+			errType:   errors.NotFoundf(""),
+		},
+		{
+			name:      "provide index inferred from the single result",
+			id:        "45_provide_index_inferred.yaml",
+			expectErr: false,
 		},
 		{
-			name:      "inject index not found",
+			name:      "inject index inferred from the single parameter",
 			id:        "4_inject_index_notfound.yaml",
 			expectErr: true,
+			errType:   errors.NotFoundf("tipo de erro esperado"),
+		},
+		{
+			name:      "group",
+			id:        "5_group_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "group inject not slice",
+			id:        "6_group_inject_not_slice.yaml",
+			expectErr: true,
 			errType:   errors.NotValidf("tipo de erro esperado"),
 		},
+		{
+			name:      "optional",
+			id:        "7_optional_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "as",
+			id:        "8_as_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "multiple results",
+			id:        "9_multiple_results_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "provide error result",
+			id:        "10_provide_error_result.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf("tipo de erro esperado"),
+		},
+		{
+			name:      "method",
+			id:        "11_method_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "module",
+			id:        "12_module_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "module conflict",
+			id:        "13_module_conflict.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf("tipo de erro esperado"),
+		},
+		{
+			name:      "module invalid name",
+			id:        "36_module_invalid_name.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf(""),
+		},
+		{
+			name:      "module name collision",
+			id:        "37_module_name_collision.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf(""),
+		},
+		{
+			name:      "decorate",
+			id:        "14_decorate_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "decorate twice",
+			id:        "15_decorate_twice.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf("tipo de erro esperado"),
+		},
+		{
+			name:      "supply",
+			id:        "16_supply_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "supply untyped",
+			id:        "17_supply_untyped.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf("tipo de erro esperado"),
+		},
+		{
+			name:      "lifecycle hooks",
+			id:        "18_hook_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "lifecycle hook invalid signature",
+			id:        "19_hook_invalid_signature.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf("tipo de erro esperado"),
+		},
+		{
+			name:      "private",
+			id:        "20_private_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "private outside package",
+			id:        "21_private_forbidden.yaml",
+			expectErr: true,
+			errType:   errors.Forbiddenf("tipo de erro esperado"),
+		},
+		{
+			name:      "private inside module",
+			id:        "22_private_module_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "private outside module inside package",
+			id:        "41_private_module_forbidden.yaml",
+			expectErr: true,
+			errType:   errors.Forbiddenf(""),
+		},
+		{
+			name:      "inject index ambiguous",
+			id:        "23_inject_index_ambiguous.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf("tipo de erro esperado"),
+		},
+		{
+			name:      "provide index out of range",
+			id:        "24_provide_index_out_of_range.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf("tipo de erro esperado"),
+		},
+		{
+			name:      "inject by parameter name",
+			id:        "25_inject_param_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "generic instances",
+			id:        "27_generic_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "provider bound to a profile",
+			id:        "28_profile_success.yaml",
+			expectErr: true,
+			errType:   errors.NotFoundf(""),
+		},
+		{
+			name:      "duplicate providers",
+			id:        "29_provider_ambiguous.yaml",
+			expectErr: true,
+			errType:   errors.Conflictf(""),
+		},
+		{
+			name:      "primary providers",
+			id:        "30_primary_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "more than one primary provider",
+			id:        "31_primary_conflict.yaml",
+			expectErr: true,
+			errType:   errors.Conflictf(""),
+		},
+		{
+			name:      "dependencies without inject not found",
+			id:        "32_dependency_notfound.yaml",
+			expectErr: true,
+			errType:   errors.NotFoundf(""),
+		},
+		{
+			name:      "dependencies without inject",
+			id:        "33_dependency_success.yaml",
+			expectErr: false,
+		},
+		{
+			name:      "dependency cycle",
+			id:        "35_cycle.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf(""),
+		},
+		{
+			name:      "self dependency",
+			id:        "38_self_dependency.yaml",
+			expectErr: true,
+			errType:   errors.NotValidf(""),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -201,3 +491,422 @@ func (suite *NewGraphFromEntriesTestSuite) TestNewGraphFromEntries() {
 		})
 	}
 }
+
+func (suite *NewGraphFromEntriesTestSuite) TestGroupEdges() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["5_group_success.yaml"])
+	suite.Require().NoError(err)
+
+	vertex := graph.vertices["github.com/americanas-go/inject/examples/simple_Serve"]
+	suite.Require().NotNil(vertex)
+
+	var keys []string
+	for _, v := range vertex.Incoming() {
+		keys = append(keys, v.Key)
+	}
+
+	suite.ElementsMatch([]string{
+		"github.com/americanas-go/inject/examples/simple_NewUserHandler",
+		"github.com/americanas-go/inject/examples/simple_NewAdminHandlers",
+	}, keys)
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestOptionalEdges() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["7_optional_success.yaml"])
+	suite.Require().NoError(err)
+
+	attr := graph.EdgeAttr(
+		"github.com/americanas-go/inject/examples/simple_FooBar",
+		"github.com/americanas-go/inject/examples/simple_Report")
+	suite.True(attr.Optional)
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestMultipleResults() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["9_multiple_results_success.yaml"])
+	suite.Require().NoError(err)
+
+	vertex := graph.vertices["github.com/acme/app/db_NewClients"]
+	suite.Require().NotNil(vertex)
+	suite.ElementsMatch([]string{"*db.Client_named_read", "*db.Client_named_write"}, vertex.Value.Provides)
+
+	var keys []string
+	for _, v := range vertex.Adjacent() {
+		keys = append(keys, v.Key)
+	}
+	suite.Equal([]string{"github.com/acme/app/db_Migrate"}, keys)
+
+	// the annotation kept is the one of the first identity, on every build
+	for i := 0; i < 20; i++ {
+		graph, err := NewGraphFromEntries(context.Background(), suite.testData["9_multiple_results_success.yaml"])
+		suite.Require().NoError(err)
+		suite.Equal("read", graph.vertices["github.com/acme/app/db_NewClients"].Value.An.Name)
+	}
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestVisibilityErrorIsStable() {
+	private := []annotation.Annotation{{Name: "Private"}, {Name: "Provide"}}
+	components := []Component{
+		{Entry: annotation.Entry{Path: "github.com/acme/app/a", Func: annotation.Func{Name: "NewA"}, Annotations: private}},
+		{Entry: annotation.Entry{Path: "github.com/acme/app/b", Func: annotation.Func{Name: "NewB"}, Annotations: private}},
+		{Entry: annotation.Entry{Path: "github.com/acme/app/cmd", Func: annotation.Func{Name: "Run"}}},
+	}
+
+	for i := 0; i < 20; i++ {
+		graph := NewGraph[Component]()
+		for _, component := range components {
+			graph.AddVertex(gid(component.Entry), component)
+		}
+		graph.AddEdge("github.com/acme/app/b_NewB", "github.com/acme/app/cmd_Run")
+		graph.AddEdge("github.com/acme/app/a_NewA", "github.com/acme/app/cmd_Run")
+
+		err := validateVisibility(graph)
+		suite.Require().Error(err)
+		suite.Contains(err.Error(), "the private provider github.com/acme/app/a_NewA")
+	}
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestMethodReceiver() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["11_method_success.yaml"])
+	suite.Require().NoError(err)
+
+	vertex := graph.vertices["github.com/acme/app/client_Factory_NewClient"]
+	suite.Require().NotNil(vertex)
+
+	var keys []string
+	for _, v := range vertex.Incoming() {
+		keys = append(keys, v.Key)
+	}
+	suite.ElementsMatch([]string{
+		"github.com/acme/app/client_NewFactory",
+		"github.com/acme/app/client_NewConfig",
+	}, keys)
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestModuleScope() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["12_module_success.yaml"])
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name     string
+		key      string
+		expected string
+	}{
+		{"Package Scope", "github.com/acme/app/payments_NewGateway", "payments"},
+		{"Narrower Func Scope", "github.com/acme/app/payments_NewCharger", "charges"},
+		{"Out Of Scope", "github.com/acme/app/cmd_Serve", ""},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			vertex := graph.vertices[tc.key]
+			suite.Require().NotNil(vertex)
+
+			name := ""
+			if vertex.Value.Module != nil {
+				name = vertex.Value.Module.Name
+			}
+			suite.Equal(tc.expected, name)
+		})
+	}
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestModulePackageScope() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["42_module_package_scope.yaml"])
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name     string
+		key      string
+		expected string
+	}{
+		{"Package Of The Anchor", "github.com/acme/app/api/http_NewRouter", "api"},
+		{"Subpackage", "github.com/acme/app/api/http/middleware_NewLogger", "api"},
+		{"Package With The Same Name", "github.com/acme/app/web/http_NewServer", ""},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			vertex := graph.vertices[tc.key]
+			suite.Require().NotNil(vertex)
+
+			name := ""
+			if vertex.Value.Module != nil {
+				name = vertex.Value.Module.Name
+			}
+			suite.Equal(tc.expected, name)
+		})
+	}
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestDecorator() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["14_decorate_success.yaml"])
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name         string
+		key          string
+		expectedKeys []string
+	}{
+		{"Decorator Takes The Provider", "github.com/acme/app/client_WithTracing", []string{
+			"github.com/acme/app/client_NewClient",
+			"github.com/acme/app/client_NewTracer",
+		}},
+		{"Consumer Takes The Decorator", "github.com/acme/app/cmd_Fetch", []string{
+			"github.com/acme/app/client_WithTracing",
+		}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			var keys []string
+			for _, v := range graph.vertices[tc.key].Incoming() {
+				keys = append(keys, v.Key)
+			}
+			suite.ElementsMatch(tc.expectedKeys, keys)
+		})
+	}
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestDecoratorEdgeIsStable() {
+	entries := suite.testData["14_decorate_success.yaml"]
+
+	// the decorator must be linked to its consumers whatever the order of
+	// the entries and of the map iteration
+	reversed := make([]annotation.Entry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		reversed = append(reversed, entries[i])
+	}
+
+	for i := 0; i < 50; i++ {
+		graph, err := NewGraphFromEntries(context.Background(), [][]annotation.Entry{entries, reversed}[i%2])
+		suite.Require().NoError(err)
+
+		var keys []string
+		for _, v := range graph.vertices["github.com/acme/app/cmd_Fetch"].Incoming() {
+			keys = append(keys, v.Key)
+		}
+		suite.Require().Equal([]string{"github.com/acme/app/client_WithTracing"}, keys)
+	}
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestHooks() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["18_hook_success.yaml"])
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name         string
+		key          string
+		expectedKeys []string
+	}{
+		{"Method Hook Takes The Receiver", "github.com/acme/app/db_DB_Close", []string{
+			"github.com/acme/app/db_NewDB",
+		}},
+		{"Function Hook Takes The Bound Type And Dependency Hooks", "github.com/acme/app/server_Listen", []string{
+			"github.com/acme/app/server_NewServer",
+			"github.com/acme/app/db_DB_Close",
+		}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			var keys []string
+			for _, v := range graph.vertices[tc.key].Incoming() {
+				keys = append(keys, v.Key)
+			}
+			suite.ElementsMatch(tc.expectedKeys, keys)
+			suite.True(graph.vertices[tc.key].Value.Lifecycle)
+		})
+	}
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestObjects() {
+	entries := suite.testData["26_objects_success.yaml"]
+
+	objects := []Object{
+		{Path: "github.com/acme/app/user", Package: "user", Name: "Params", Fields: []Field{
+			{Name: "Reader", Type: "*Repo", An: Annotation{Name: "read"}},
+			{Name: "Cache", Type: "*Cache", An: Annotation{Optional: true}},
+			{Name: "Handlers", Type: "[]Handler", An: Annotation{Group: "handlers"}},
+		}},
+		{Path: "github.com/acme/app/user", Package: "user", Name: "Result", Out: true, Fields: []Field{
+			{Name: "Reader", Type: "*Repo", An: Annotation{Name: "read"}},
+			{Name: "Handlers", Type: "[]Handler", An: Annotation{Group: "handlers", Flatten: true}},
+		}},
+	}
+
+	_, err := NewGraphFromEntries(context.Background(), entries)
+	suite.Error(err)
+	suite.IsType(errors.NotFoundf("tipo de erro esperado"), err)
+
+	graph, err := NewGraphFromEntries(context.Background(), entries, WithObjects(objects))
+	suite.Require().NoError(err)
+
+	suite.ElementsMatch([]string{"*user.Repo_named_read", "user.Handler_grouped_handlers"}, graph.vertices["github.com/acme/app/user_NewRepos"].Value.Provides)
+
+	var keys []string
+	for _, v := range graph.vertices["github.com/acme/app/user_NewService"].Incoming() {
+		keys = append(keys, v.Key)
+	}
+	suite.Equal([]string{"github.com/acme/app/user_NewRepos"}, keys)
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestGenericInstances() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["27_generic_success.yaml"])
+	suite.Require().NoError(err)
+
+	suite.Equal([]string{"*cache.Store[user.User]_default"}, graph.vertices["github.com/acme/app/cache_NewStore[user.User]"].Value.Provides)
+	suite.Equal([]string{"*cache.Store[order.Order]_default"}, graph.vertices["github.com/acme/app/cache_NewStore[order.Order]"].Value.Provides)
+
+	var keys []string
+	for _, v := range graph.vertices["github.com/acme/app/user_NewService"].Incoming() {
+		keys = append(keys, v.Key)
+	}
+	suite.Equal([]string{"github.com/acme/app/cache_NewStore[user.User]"}, keys)
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestProfiles() {
+	entries := suite.testData["28_profile_success.yaml"]
+
+	suite.Equal([]string{"local", "prod"}, Profiles(entries))
+
+	graphs, err := NewGraphsFromEntries(context.Background(), entries)
+	suite.Require().NoError(err)
+	suite.Len(graphs, 2)
+
+	testCases := []struct {
+		name         string
+		profile      string
+		expectedKeys []string
+		missing      []string
+	}{
+		{"Local Profile", "local", []string{
+			"github.com/acme/app/queue_NewMemoryQueue",
+		}, []string{"github.com/acme/app/queue_NewSQSQueue"}},
+		{"Prod Profile", "prod", []string{
+			"github.com/acme/app/queue_NewSQSQueue",
+		}, []string{"github.com/acme/app/queue_NewMemoryQueue"}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			graph := graphs[tc.profile]
+			suite.Require().NotNil(graph)
+
+			var keys []string
+			for _, v := range graph.vertices["github.com/acme/app/worker_Run"].Incoming() {
+				keys = append(keys, v.Key)
+			}
+			suite.Equal(tc.expectedKeys, keys)
+
+			for _, key := range tc.missing {
+				suite.NotContains(graph.vertices, key)
+			}
+		})
+	}
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestProfileModifiers() {
+	entries := suite.testData["43_profile_private_primary.yaml"]
+
+	testCases := []struct {
+		name         string
+		profile      string
+		expectedKeys []string
+		missing      []string
+	}{
+		{"Without The Modified Provides", "local", []string{
+			"github.com/acme/app/queue_NewDefaultQueue",
+		}, []string{"github.com/acme/app/queue_NewSQSQueue", "github.com/acme/app/queue_NewSQSConfig"}},
+		{"With The Modified Provides", "prod", []string{
+			"github.com/acme/app/queue_NewSQSQueue",
+		}, []string{"github.com/acme/app/queue_NewDefaultQueue"}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			graph, err := NewGraphFromEntries(context.Background(), entries, WithProfile(tc.profile))
+			suite.Require().NoError(err)
+
+			var keys []string
+			for _, v := range graph.vertices["github.com/acme/app/worker_Run"].Incoming() {
+				keys = append(keys, v.Key)
+			}
+			suite.Equal(tc.expectedKeys, keys)
+
+			for _, key := range tc.missing {
+				suite.NotContains(graph.vertices, key)
+			}
+		})
+	}
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestPrimary() {
+	_, err := NewGraphFromEntries(context.Background(), suite.testData["29_provider_ambiguous.yaml"])
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "github.com/acme/app/cache.NewMemoryCache in memory.go, github.com/acme/app/cache.NewRedisCache in redis.go")
+
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["30_primary_success.yaml"])
+	suite.Require().NoError(err)
+
+	var keys []string
+	for _, v := range graph.vertices["github.com/acme/app/user_NewService"].Incoming() {
+		keys = append(keys, v.Key)
+	}
+	suite.ElementsMatch([]string{"github.com/acme/app/cache_NewRedisCache", "github.com/acme/app/store_NewPostgres"}, keys)
+
+	suite.NotContains(graph.vertices, "github.com/acme/app/cache_NewMemoryCache")
+	suite.NotContains(graph.vertices, "github.com/acme/app/store_NewSqlite")
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestPrimaryLoserDependencies() {
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["44_primary_loser_dependencies.yaml"])
+	suite.Require().NoError(err)
+
+	suite.NotContains(graph.vertices, "github.com/acme/app/cache_NewMemoryCache")
+	suite.Equal([]string{"github.com/acme/app/cache_NewRedisCache"}, keysOf(graph.vertices["github.com/acme/app/config_NewConfig"].Adjacent()))
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestDependencies() {
+	_, err := NewGraphFromEntries(context.Background(), suite.testData["32_dependency_notfound.yaml"])
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "app.Logger_default required by the parameter l of github.com/acme/app/app.Run")
+	suite.Contains(err.Error(), "app.Metrics_default required by the parameter m of github.com/acme/app/app.NewClient")
+
+	graph, err := NewGraphFromEntries(context.Background(), suite.testData["33_dependency_success.yaml"])
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name         string
+		key          string
+		expectedKeys []string
+	}{
+		{"Provide", "github.com/acme/app/app_NewClient", []string{
+			"github.com/acme/app/app_NewConfig",
+			"github.com/acme/app/app_NewMetrics",
+		}},
+		{"Invoke Takes Builtins And Skips Variadics", "github.com/acme/app/app_Run", []string{
+			"go.uber.org/fx_Lifecycle",
+			"github.com/acme/app/app_NewClient",
+			"github.com/acme/app/app_NewLogger",
+		}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			var keys []string
+			for _, v := range graph.vertices[tc.key].Incoming() {
+				keys = append(keys, v.Key)
+			}
+			suite.ElementsMatch(tc.expectedKeys, keys)
+		})
+	}
+}
+
+func (suite *NewGraphFromEntriesTestSuite) TestCycle() {
+	_, err := NewGraphFromEntries(context.Background(), suite.testData["35_cycle.yaml"])
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "github.com/acme/app/a_NewA -(*a.A_default)-> github.com/acme/app/b_NewB -(*b.B_default)-> github.com/acme/app/a_NewA")
+
+	_, err = NewGraphFromEntries(context.Background(), suite.testData["38_self_dependency.yaml"])
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "github.com/acme/app/a_NewA -(*a.A_default)-> github.com/acme/app/a_NewA")
+}
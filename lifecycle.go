@@ -0,0 +1,104 @@
+package inject
+
+import (
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"strings"
+)
+
+// Hook describes a lifecycle hook bound to a provided type.
+type Hook struct {
+	Event string // OnStart or OnStop.
+	Type  string // bound type, as declared in the entry.
+	Index int    // parameter taking the bound type, or -1 for the method receiver.
+}
+
+// newHook validates the signature of a lifecycle hook entry. Methods take only
+// a context.Context and are bound to their receiver, while functions take a
+// context.Context followed by the bound type. Both return only an error.
+func newHook(entry annotation.Entry, annType AnnotationType) (*Hook, error) {
+	event := "OnStart"
+	if annType == AnnotationTypeONSTOP {
+		event = "OnStop"
+	}
+
+	results := entry.Func.Results
+	if len(results) != 1 || results[0].Type != "error" {
+		return nil, errors.NotValidf("the hook must return only an error, found on the annotation %s in the entry %s.%s", event, entry.Path, entry.Func.Name)
+	}
+
+	params := entry.Func.Parameters
+	if len(params) == 0 || params[0].Type != "context.Context" {
+		return nil, errors.NotValidf("the hook must take a context.Context as its first parameter, found on the annotation %s in the entry %s.%s", event, entry.Path, entry.Func.Name)
+	}
+
+	if entry.Struct != "" {
+		if len(params) != 1 {
+			return nil, errors.NotValidf("the hook method must take only a context.Context, found on the annotation %s in the entry %s.%s", event, entry.Path, entry.Func.Name)
+		}
+
+		return &Hook{Event: event, Type: entry.Struct, Index: -1}, nil
+	}
+
+	if len(params) != 2 {
+		return nil, errors.NotValidf("the hook function must take a context.Context and the bound type, found on the annotation %s in the entry %s.%s", event, entry.Path, entry.Func.Name)
+	}
+
+	return &Hook{Event: event, Type: params[1].Type, Index: 1}, nil
+}
+
+// getHook returns the lifecycle hook of the entry, or nil when it has none.
+func getHook(entry annotation.Entry) (*Hook, error) {
+	for _, ann := range entry.Annotations {
+		annType, err := ParseAnnotationType(strings.ToUpper(ann.Name))
+		if err != nil {
+			continue
+		}
+
+		if annType == AnnotationTypeONSTART || annType == AnnotationTypeONSTOP {
+			return newHook(entry, annType)
+		}
+	}
+
+	return nil, nil
+}
+
+// injectAt returns the inject annotation of the entry parameter at index, or
+// the zero annotation when the parameter is not annotated.
+func injectAt(entry annotation.Entry, index int) (Annotation, bool, error) {
+	for _, ann := range entry.Annotations {
+		if strings.ToUpper(ann.Name) != AnnotationTypeINJECT.String() {
+			continue
+		}
+
+		a, err := decodeAnnotation(entry, ann)
+		if err != nil {
+			return a, false, err
+		}
+
+		if a.Index != nil && *a.Index == index {
+			return a, true, nil
+		}
+	}
+
+	return Annotation{}, false, nil
+}
+
+// orderHooks links the hooks bound to components that depend on each other,
+// so the hooks of a dependency are appended first. Since fx runs the OnStop
+// hooks in reverse order, components are stopped before their dependencies.
+func orderHooks(graph *Graph[Component], hooks []Component, bound map[string]string) {
+	for _, h1 := range hooks {
+		for _, h2 := range hooks {
+			p1, ok1 := bound[gid(h1.Entry)]
+			p2, ok2 := bound[gid(h2.Entry)]
+			if !ok1 || !ok2 || p1 == p2 {
+				continue
+			}
+
+			if graph.Reachable(p1, p2) {
+				graph.AddEdge(gid(h1.Entry), gid(h2.Entry))
+			}
+		}
+	}
+}
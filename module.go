@@ -0,0 +1,212 @@
+package inject
+
+import (
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"go/token"
+	"strings"
+)
+
+// Module represents a named fx module bundling every component under the
+// scope of its anchor entry.
+type Module struct {
+	Name  string
+	Scope ModuleAttr
+	Entry annotation.Entry
+}
+
+// Contains reports whether the entry is under the scope of the module. The
+// package scope covers the package of the anchor along with its subpackages,
+// while the path scope only covers the package of the anchor.
+func (m *Module) Contains(entry annotation.Entry) bool {
+	switch m.Scope {
+	case ModuleAttrMODULE:
+		return entry.Module == m.Entry.Module
+	case ModuleAttrPATH:
+		return entry.Path == m.Entry.Path
+	case ModuleAttrPACKAGE:
+		return entry.Path == m.Entry.Path || strings.HasPrefix(entry.Path, m.Entry.Path+"/")
+	case ModuleAttrFUNC:
+		return gid(entry) == gid(m.Entry)
+	}
+
+	return false
+}
+
+// specificity orders the scopes from the widest to the narrowest one.
+var specificity = map[ModuleAttr]int{
+	ModuleAttrMODULE:  0,
+	ModuleAttrPACKAGE: 1,
+	ModuleAttrPATH:    2,
+	ModuleAttrFUNC:    3,
+}
+
+func newModule(entry annotation.Entry, a Annotation) (*Module, error) {
+	if a.Name == "" {
+		return nil, errors.NotValidf("the name parameter is required on the module annotation in the entry %s.%s", entry.Path, entry.Func.Name)
+	}
+
+	// the name is part of the generated function and file names
+	if !token.IsIdentifier(a.Name) {
+		return nil, errors.NotValidf("the name %s must be an identifier on the module annotation in the entry %s.%s", a.Name, entry.Path, entry.Func.Name)
+	}
+
+	scope := ModuleAttrPATH
+	if a.Scope != "" {
+		var err error
+		scope, err = ParseModuleAttr(strings.ToUpper(a.Scope))
+		if err != nil {
+			return nil, errors.NotValidf("the scope %s is invalid on the module %s in the entry %s.%s", a.Scope, a.Name, entry.Path, entry.Func.Name)
+		}
+	}
+
+	if scope == ModuleAttrFUNC && !entry.IsFunc() {
+		return nil, errors.NotValidf("the scope %s requires a function on the module %s in the entry %s", a.Scope, a.Name, entry.Path)
+	}
+
+	return &Module{
+		Name:  a.Name,
+		Scope: scope,
+		Entry: entry,
+	}, nil
+}
+
+func newModules(entry annotation.Entry) ([]*Module, error) {
+	var modules []*Module
+	for _, ann := range entry.Annotations {
+		if strings.ToUpper(ann.Name) != AnnotationTypeMODULE.String() {
+			continue
+		}
+
+		a := Annotation{}
+		err := ann.Decode(&a)
+		if err != nil {
+			return nil, err
+		}
+
+		module, err := newModule(entry, a)
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, module)
+	}
+
+	return modules, nil
+}
+
+// moduleOf returns the narrowest module containing the entry, or nil when the
+// entry is not under any module scope.
+func moduleOf(modules []*Module, entry annotation.Entry) (*Module, error) {
+	var found *Module
+	for _, module := range modules {
+		if !module.Contains(entry) {
+			continue
+		}
+
+		if found == nil || specificity[module.Scope] > specificity[found.Scope] {
+			found = module
+			continue
+		}
+
+		if specificity[module.Scope] == specificity[found.Scope] && module.Name != found.Name {
+			return nil, errors.NotValidf("the entry %s.%s is under the scope of the modules %s and %s", entry.Path, entry.Func.Name, found.Name, module.Name)
+		}
+	}
+
+	return found, nil
+}
+
+// validateModules ensures that modules sharing a name share their scope too.
+func validateModules(modules []*Module) error {
+	byName := make(map[string]*Module)
+	for _, module := range modules {
+		other, ok := byName[module.Name]
+		if !ok {
+			byName[module.Name] = module
+			continue
+		}
+
+		if other.Scope != module.Scope || other.Entry.Path != module.Entry.Path {
+			return errors.NotValidf("the module %s is declared with different scopes in %s and %s", module.Name, other.Entry.Path, module.Entry.Path)
+		}
+	}
+
+	return nil
+}
+
+// validateModuleNames ensures that the modules generated for the named modules
+// don't collide with the ones generated for the components of their package.
+func validateModuleNames(graph *Graph[Component], modules []*Module) error {
+	for _, module := range modules {
+		for _, key := range graph.sortedKeys() {
+			entry := graph.vertices[key].Value.Entry
+			if graph.vertices[key].Value.External || entry.Path != module.Entry.Path {
+				continue
+			}
+
+			// the file names are lower case
+			if strings.EqualFold(getName(entry), getModuleName(module)) {
+				return errors.NotValidf("the module %s collides with the component %s in %s", module.Name, key, module.Entry.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isPrivate reports whether the results of the entry are only visible inside
+// its scope, either by the private annotation or by the private parameter of
+// one of its provides. Since fx.Private applies to the whole constructor, it
+// covers every result of the entry.
+func isPrivate(entry annotation.Entry) bool {
+	for _, ann := range entry.Annotations {
+		switch strings.ToUpper(ann.Name) {
+		case AnnotationTypePRIVATE.String():
+			return true
+		case AnnotationTypePROVIDE.String():
+			a := Annotation{}
+			if err := ann.Decode(&a); err == nil && a.Private {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// visible reports whether the results of the provider are visible to the
+// consumer. Private providers are only visible inside their module, or inside
+// their package when neither of them is bundled by a module.
+func visible(provider, consumer Component) bool {
+	if !isPrivate(provider.Entry) {
+		return true
+	}
+
+	if provider.Module != nil {
+		return consumer.Module != nil && consumer.Module.Name == provider.Module.Name
+	}
+
+	return consumer.Module == nil && consumer.Entry.Path == provider.Entry.Path
+}
+
+// validateVisibility ensures that private providers are only consumed inside
+// their scope.
+func validateVisibility(graph *Graph[Component]) error {
+	for _, key := range graph.sortedKeys() {
+		vertex := graph.vertices[key]
+		for _, v := range vertex.Adjacent() {
+			if visible(vertex.Value, v.Value) {
+				continue
+			}
+
+			scope := vertex.Value.Entry.Path
+			if vertex.Value.Module != nil {
+				scope = vertex.Value.Module.Name
+			}
+
+			return errors.Forbiddenf("the private provider %s is not visible to %s outside the scope %s", vertex.Key, v.Key, scope)
+		}
+	}
+
+	return nil
+}
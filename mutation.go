@@ -0,0 +1,122 @@
+package inject
+
+// RemoveEdge removes the directed edge from one vertex to another.
+// If the edge does not exist, it logs a warning.
+func (g *Graph[T]) RemoveEdge(fromKey, toKey string) {
+	if _, ok := g.attrs[fromKey][toKey]; !ok {
+		log.Warnf("edge not found from %v to %v", fromKey, toKey)
+		return
+	}
+
+	g.edges[fromKey] = removeVertex(g.edges[fromKey], toKey)
+	g.incoming[toKey] = removeVertex(g.incoming[toKey], fromKey)
+	delete(g.attrs[fromKey], toKey)
+	g.incomingEdges[toKey]--
+
+	if len(g.edges[fromKey]) == 0 {
+		delete(g.edges, fromKey)
+		delete(g.attrs, fromKey)
+	}
+	if len(g.incoming[toKey]) == 0 {
+		delete(g.incoming, toKey)
+	}
+
+	log.Debugf("edge removed from %v to %v", fromKey, toKey)
+}
+
+// RemoveVertex removes the vertex along with its edges. The removed vertex no
+// longer refers to the graph. If the vertex does not exist, it logs a warning.
+func (g *Graph[T]) RemoveVertex(key string) {
+	vertex, ok := g.vertices[key]
+	if !ok {
+		log.Warnf("vertex not found. %v ", key)
+		return
+	}
+
+	for _, v := range append([]*Vertex[T]{}, g.edges[key]...) {
+		g.RemoveEdge(key, v.Key)
+	}
+
+	for _, v := range append([]*Vertex[T]{}, g.incoming[key]...) {
+		g.RemoveEdge(v.Key, key)
+	}
+
+	delete(g.vertices, key)
+	delete(g.incomingEdges, key)
+	vertex.graph = nil
+
+	log.Debugf("vertex removed: %s", key)
+}
+
+// Clone returns a copy of the graph with vertices of its own. Values are
+// copied as they are, so values holding pointers are shared by both graphs.
+func (g *Graph[T]) Clone() *Graph[T] {
+	return g.Induced(func(*Vertex[T]) bool { return true })
+}
+
+// Subgraph returns a copy of the graph with the given vertices along with
+// every vertex they depend on, such as the components an invoke needs to run.
+// Keys not found in the graph are skipped.
+func (g *Graph[T]) Subgraph(keys ...string) *Graph[T] {
+	var found []string
+	for _, key := range keys {
+		if _, ok := g.vertices[key]; !ok {
+			log.Warnf("vertex not found. %v ", key)
+			continue
+		}
+		found = append(found, key)
+	}
+
+	kept := g.closure(found, g.incoming)
+	for _, key := range found {
+		kept[key] = struct{}{}
+	}
+
+	return g.Induced(func(v *Vertex[T]) bool {
+		_, ok := kept[v.Key]
+		return ok
+	})
+}
+
+// Induced returns a copy of the graph with the vertices matching the
+// predicate, along with the edges between them.
+func (g *Graph[T]) Induced(predicate func(*Vertex[T]) bool) *Graph[T] {
+	induced := NewGraph[T]()
+
+	keys := g.sortedKeys()
+	for _, key := range keys {
+		if predicate(g.vertices[key]) {
+			induced.AddVertex(key, g.vertices[key].Value)
+		}
+	}
+
+	for _, key := range keys {
+		if _, ok := induced.vertices[key]; !ok {
+			continue
+		}
+
+		for _, v := range g.edges[key] {
+			if _, ok := induced.vertices[v.Key]; !ok {
+				continue
+			}
+
+			attr := g.attrs[key][v.Key]
+			attr.Types = append([]string(nil), attr.Types...)
+			induced.AddEdgeWithAttr(key, v.Key, attr)
+		}
+	}
+
+	return induced
+}
+
+// removeVertex removes the vertex with the key from a list of vertices,
+// keeping the order of the others.
+func removeVertex[T any](vertices []*Vertex[T], key string) []*Vertex[T] {
+	for i, v := range vertices {
+		if v.Key == key {
+			return append(vertices[:i:i], vertices[i+1:]...)
+		}
+	}
+
+	return vertices
+}
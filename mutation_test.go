@@ -0,0 +1,116 @@
+package inject
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MutationTestSuite struct {
+	suite.Suite
+}
+
+func TestMutationTestSuite(t *testing.T) {
+	suite.Run(t, new(MutationTestSuite))
+}
+
+// diamond is the graph a -> b -> d, a -> c -> d, d -> e.
+var diamond = [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}, {"d", "e"}}
+
+// requireConsistent checks the edge lists, the reverse index and the counts
+// of incoming edges agree, and every vertex refers to the graph.
+func (suite *MutationTestSuite) requireConsistent(g *Graph[string]) {
+	incoming := make(map[string][]string)
+	for key, edges := range g.edges {
+		suite.Contains(g.vertices, key)
+		for _, v := range edges {
+			suite.Same(g.vertices[v.Key], v)
+			suite.Contains(g.attrs[key], v.Key)
+			incoming[v.Key] = append(incoming[v.Key], key)
+		}
+	}
+
+	for key, v := range g.vertices {
+		suite.Same(g, v.graph)
+		suite.Equal(len(incoming[key]), g.InDegree(key))
+		suite.ElementsMatch(incoming[key], keysOf(v.Incoming()))
+	}
+}
+
+func (suite *MutationTestSuite) TestRemoveEdge() {
+	g := newGraph(diamond)
+	g.RemoveEdge("b", "d")
+	g.RemoveEdge("b", "e")
+
+	suite.requireConsistent(g)
+	suite.Empty(g.vertices["b"].Adjacent())
+	suite.Equal([]string{"c"}, keysOf(g.vertices["d"].Incoming()))
+	suite.Equal(EdgeAttr{}, g.EdgeAttr("b", "d"))
+}
+
+func (suite *MutationTestSuite) TestRemoveVertex() {
+	g := newGraph(diamond)
+	d := g.vertices["d"]
+	g.RemoveVertex("d")
+	g.RemoveVertex("z")
+
+	suite.requireConsistent(g)
+	suite.NotContains(g.vertices, "d")
+	suite.Nil(d.graph)
+	suite.Empty(g.vertices["b"].Adjacent())
+	suite.Empty(g.vertices["e"].Incoming())
+	suite.Equal([]string{"a", "e"}, keysOf(g.VerticesWithNoIncomingEdges()))
+}
+
+func (suite *MutationTestSuite) TestClone() {
+	g := newGraph(diamond)
+	clone := g.Clone()
+	suite.requireConsistent(clone)
+
+	clone.RemoveVertex("a")
+	clone.AddEdgeWithAttr("b", "e", EdgeAttr{Types: []string{"be"}})
+
+	suite.requireConsistent(g)
+	suite.Contains(g.vertices, "a")
+	suite.Equal([]string{"d"}, keysOf(g.vertices["b"].Adjacent()))
+	suite.Equal(EdgeAttr{Types: []string{"de"}}, clone.EdgeAttr("d", "e"))
+}
+
+func (suite *MutationTestSuite) TestSubgraph() {
+	testCases := []struct {
+		name     string
+		keys     []string
+		expected []string
+		edges    int
+	}{
+		{"Dependencies Of A Vertex", []string{"d"}, []string{"a", "b", "c", "d"}, 4},
+		{"Dependencies Of Many Vertices", []string{"b", "c"}, []string{"a", "b", "c"}, 2},
+		{"Missing Vertex", []string{"z", "a"}, []string{"a"}, 0},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			sub := newGraph(diamond).Subgraph(tc.keys...)
+			suite.requireConsistent(sub)
+			suite.Equal(tc.expected, sub.sortedKeys())
+
+			edges := 0
+			for _, key := range sub.sortedKeys() {
+				edges += sub.OutDegree(key)
+			}
+			suite.Equal(tc.edges, edges)
+		})
+	}
+}
+
+func (suite *MutationTestSuite) TestInduced() {
+	induced := newGraph(diamond).Induced(func(v *Vertex[string]) bool {
+		return v.Key != "b"
+	})
+
+	suite.requireConsistent(induced)
+	suite.Equal([]string{"a", "c", "d", "e"}, induced.sortedKeys())
+	suite.Equal([]string{"c"}, keysOf(induced.vertices["a"].Adjacent()))
+	suite.Equal([]string{"c"}, keysOf(induced.vertices["d"].Incoming()))
+	suite.Equal(EdgeAttr{Types: []string{"cd"}}, induced.EdgeAttr("c", "d"))
+}
@@ -0,0 +1,51 @@
+package inject
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Object is a parameter or result struct, embedding fx.In or fx.Out, whose
+// fields are dependencies or provisions on their own.
+type Object struct {
+	Path    string
+	Package string
+	File    string
+	Name    string
+	Out     bool // whether the struct embeds fx.Out instead of fx.In.
+	Fields  []Field
+}
+
+// Field is an exported field of an object, along with the annotation built
+// from its struct tag.
+type Field struct {
+	Name string
+	Type string
+	An   Annotation
+}
+
+// parseTag builds the annotation of a field from its name, group and optional
+// struct tags.
+func parseTag(tag string) Annotation {
+	st := reflect.StructTag(tag)
+
+	a := Annotation{
+		Name:     st.Get("name"),
+		Optional: st.Get("optional") == "true",
+	}
+
+	if group := st.Get("group"); group != "" {
+		opts := strings.Split(group, ",")
+		a.Group = opts[0]
+		for _, opt := range opts[1:] {
+			switch opt {
+			case "flatten":
+				a.Flatten = true
+			case "soft":
+				a.Soft = true
+			}
+		}
+	}
+
+	return a
+}
@@ -0,0 +1,87 @@
+package inject
+
+import (
+	"sort"
+)
+
+// TopologicalSort returns the vertices ordered so every vertex comes after the
+// vertices it depends on. Ties are broken by key, so the order is the same on
+// every run. It fails when the graph has a cycle.
+func (g *Graph[T]) TopologicalSort() ([]*Vertex[T], error) {
+	layers, err := g.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	var sorted []*Vertex[T]
+	for _, layer := range layers {
+		sorted = append(sorted, layer...)
+	}
+
+	return sorted, nil
+}
+
+// Layers groups the vertices by depth, the length of the longest path reaching
+// them from a vertex with no incoming edges. Each layer only depends on the
+// previous ones and is sorted by key. It fails when the graph has a cycle.
+func (g *Graph[T]) Layers() ([][]*Vertex[T], error) {
+	err := g.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	degrees := make(map[string]int)
+	for key, count := range g.incomingEdges {
+		degrees[key] = count
+	}
+
+	var current []string
+	for _, key := range g.sortedKeys() {
+		if degrees[key] == 0 {
+			current = append(current, key)
+		}
+	}
+
+	var layers [][]*Vertex[T]
+	for len(current) > 0 {
+		var layer []*Vertex[T]
+		var next []string
+		for _, key := range current {
+			layer = append(layer, g.vertices[key])
+
+			for _, v := range g.edges[key] {
+				degrees[v.Key]--
+				if degrees[v.Key] == 0 {
+					next = append(next, v.Key)
+				}
+			}
+		}
+
+		layers = append(layers, layer)
+		current = sortKeys(next)
+	}
+
+	return layers, nil
+}
+
+// orderedVertices returns the vertices in topological order, or by key when
+// the graph has a cycle, so they can be listed in a stable order anyway.
+func (g *Graph[T]) orderedVertices() []*Vertex[T] {
+	sorted, err := g.TopologicalSort()
+	if err == nil {
+		return sorted
+	}
+
+	var vertices []*Vertex[T]
+	for _, key := range g.sortedKeys() {
+		vertices = append(vertices, g.vertices[key])
+	}
+
+	return vertices
+}
+
+// sortKeys sorts the keys in place and returns them.
+func sortKeys(keys []string) []string {
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,98 @@
+package inject
+
+import (
+	"github.com/americanas-go/errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderTestSuite struct {
+	suite.Suite
+}
+
+func TestOrderTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderTestSuite))
+}
+
+// newGraph builds a graph of the vertices linked by the edges, each edge
+// providing the type named after its ends.
+func newGraph(edges [][2]string) *Graph[string] {
+	g := NewGraph[string]()
+	for _, edge := range edges {
+		for _, key := range edge {
+			if _, ok := g.vertices[key]; !ok {
+				g.AddVertex(key, key)
+			}
+		}
+		g.AddEdgeWithAttr(edge[0], edge[1], EdgeAttr{Types: []string{edge[0] + edge[1]}})
+	}
+
+	return g
+}
+
+func keysOf[T any](vertices []*Vertex[T]) []string {
+	var keys []string
+	for _, v := range vertices {
+		keys = append(keys, v.Key)
+	}
+	return keys
+}
+
+func (suite *OrderTestSuite) TestLayers() {
+	testCases := []struct {
+		name     string
+		edges    [][2]string
+		expected [][]string
+	}{
+		{"Chain", [][2]string{{"c", "b"}, {"b", "a"}}, [][]string{{"c"}, {"b"}, {"a"}}},
+		{"Diamond", [][2]string{{"d", "b"}, {"d", "c"}, {"b", "a"}, {"c", "a"}}, [][]string{{"d"}, {"b", "c"}, {"a"}}},
+		{"Longest Path Depth", [][2]string{{"a", "z"}, {"b", "c"}, {"c", "z"}}, [][]string{{"a", "b"}, {"c"}, {"z"}}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			layers, err := newGraph(tc.edges).Layers()
+			suite.NoError(err)
+
+			var keys [][]string
+			for _, layer := range layers {
+				keys = append(keys, keysOf(layer))
+			}
+			suite.Equal(tc.expected, keys)
+		})
+	}
+}
+
+func (suite *OrderTestSuite) TestTopologicalSort() {
+	sorted, err := newGraph([][2]string{{"d", "b"}, {"d", "c"}, {"b", "a"}, {"c", "a"}, {"e", "a"}}).TopologicalSort()
+	suite.NoError(err)
+	suite.Equal([]string{"d", "e", "b", "c", "a"}, keysOf(sorted))
+
+	_, err = newGraph([][2]string{{"a", "b"}, {"b", "a"}}).TopologicalSort()
+	suite.IsType(errors.NotValidf(""), err)
+}
+
+func (suite *OrderTestSuite) TestStableOutput() {
+	edges := [][2]string{{"d", "b"}, {"d", "c"}, {"b", "a"}, {"c", "a"}, {"e", "a"}}
+
+	suite.Equal([]string{"d", "e"}, keysOf(newGraph(edges).VerticesWithNoIncomingEdges()))
+
+	dir := suite.T().TempDir()
+	var exported []string
+	for i := 0; i < 5; i++ {
+		file := filepath.Join(dir, "graph.gv")
+		suite.Require().NoError(newGraph(edges).ExportToGraphviz(file))
+
+		data, err := os.ReadFile(file)
+		suite.Require().NoError(err)
+		exported = append(exported, string(data))
+	}
+
+	for _, data := range exported[1:] {
+		suite.Equal(exported[0], data)
+	}
+	suite.Contains(exported[0], "digraph G {\n\t\"d\" [label=\"d\"];\n\t\"d\" -> \"b\";\n\t\"d\" -> \"c\";\n\t\"e\" [label=\"e\"];")
+}
@@ -0,0 +1,143 @@
+package inject
+
+import (
+	"context"
+	"github.com/americanas-go/annotation"
+	ustrings "github.com/americanas-go/utils/strings"
+	"sort"
+	"strings"
+)
+
+// NewGraphsFromEntries builds and validates the graph of every profile found
+// in the entries. When no profile is declared, the only graph is keyed by an
+// empty profile.
+func NewGraphsFromEntries(ctx context.Context, entries []annotation.Entry, opts ...GraphOption) (map[string]*Graph[Component], error) {
+	graphs := make(map[string]*Graph[Component])
+
+	profiles := Profiles(entries)
+	if len(profiles) == 0 {
+		profiles = []string{""}
+	}
+
+	for _, profile := range profiles {
+		graph, err := NewGraphFromEntries(ctx, entries, append(opts, WithProfile(profile))...)
+		if err != nil {
+			log.Errorf("error building the graph of the profile %s: %v", profile, err)
+			return nil, err
+		}
+		graphs[profile] = graph
+	}
+
+	return graphs, nil
+}
+
+// Profiles returns the sorted profiles declared by the annotations of the entries.
+func Profiles(entries []annotation.Entry) []string {
+	seen := make(map[string]struct{})
+	for _, entry := range entries {
+		for _, ann := range entry.Annotations {
+			for _, profile := range profilesOf(ann) {
+				seen[profile] = struct{}{}
+			}
+		}
+	}
+
+	var profiles []string
+	for profile := range seen {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+
+	return profiles
+}
+
+// profilesOf returns the profiles an annotation is bound to, separated by
+// semicolons, or none when it applies to every profile.
+func profilesOf(ann annotation.Annotation) []string {
+	value, ok := ann.Map["profile"].(string)
+	if !ok || value == "" {
+		return nil
+	}
+
+	var profiles []string
+	for _, profile := range strings.Split(value, ";") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles
+}
+
+// inProfile returns the entries with the annotations of the profile, dropping
+// the annotations bound to other profiles, along with the private and primary
+// annotations of the provides dropped. Entries left with nothing more than
+// their injects are dropped as well.
+func inProfile(entries []annotation.Entry, profile string) []annotation.Entry {
+	var filtered []annotation.Entry
+	for _, entry := range entries {
+		var kept []annotation.Annotation
+		dropped := false
+
+		for _, ann := range entry.Annotations {
+			profiles := profilesOf(ann)
+			if len(profiles) > 0 && !ustrings.SliceContains(profiles, profile) {
+				dropped = true
+				continue
+			}
+
+			kept = append(kept, ann)
+		}
+
+		if dropped {
+			kept = withoutModifiers(kept)
+		}
+
+		role := false
+		for _, ann := range kept {
+			if strings.ToUpper(ann.Name) != AnnotationTypeINJECT.String() {
+				role = true
+			}
+		}
+
+		if dropped && !role {
+			continue
+		}
+
+		entry.Annotations = kept
+		filtered = append(filtered, entry)
+	}
+
+	return filtered
+}
+
+// withoutModifiers drops the private and primary annotations left without
+// the provide or supply they modify.
+func withoutModifiers(anns []annotation.Annotation) []annotation.Annotation {
+	provide, supply := false, false
+	for _, ann := range anns {
+		switch strings.ToUpper(ann.Name) {
+		case AnnotationTypePROVIDE.String():
+			provide = true
+		case AnnotationTypeSUPPLY.String():
+			supply = true
+		}
+	}
+
+	var kept []annotation.Annotation
+	for _, ann := range anns {
+		switch strings.ToUpper(ann.Name) {
+		case AnnotationTypePRIVATE.String():
+			if !provide {
+				continue
+			}
+		case AnnotationTypePRIMARY.String():
+			if !provide && !supply {
+				continue
+			}
+		}
+		kept = append(kept, ann)
+	}
+
+	return kept
+}
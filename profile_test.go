@@ -0,0 +1,56 @@
+package inject
+
+import (
+	"github.com/americanas-go/annotation"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProfileTestSuite struct {
+	suite.Suite
+}
+
+func TestProfileTestSuite(t *testing.T) {
+	suite.Run(t, new(ProfileTestSuite))
+}
+
+func (suite *ProfileTestSuite) TestInProfile() {
+	provide := func(profile string) annotation.Annotation {
+		return annotation.Annotation{Name: "Provide", Map: map[string]interface{}{"profile": profile}}
+	}
+	inject := annotation.Annotation{Name: "Inject", Map: map[string]interface{}{}}
+	private := annotation.Annotation{Name: "Private", Map: map[string]interface{}{}}
+	primary := annotation.Annotation{Name: "Primary", Map: map[string]interface{}{}}
+
+	entries := []annotation.Entry{
+		{Func: annotation.Func{Name: "NewShared"}, Annotations: []annotation.Annotation{provide("")}},
+		{Func: annotation.Func{Name: "NewLocal"}, Annotations: []annotation.Annotation{inject, provide("local")}},
+		{Func: annotation.Func{Name: "NewCloud"}, Annotations: []annotation.Annotation{provide("prod; staging")}},
+		{Func: annotation.Func{Name: "NewPrivate"}, Annotations: []annotation.Annotation{private, provide("prod")}},
+		{Func: annotation.Func{Name: "NewPrimary"}, Annotations: []annotation.Annotation{provide("prod"), primary}},
+	}
+
+	testCases := []struct {
+		name     string
+		profile  string
+		expected []string
+	}{
+		{"No Profile", "", []string{"NewShared"}},
+		{"Local Profile", "local", []string{"NewShared", "NewLocal"}},
+		{"Staging Profile", "staging", []string{"NewShared", "NewCloud"}},
+		{"Prod Profile", "prod", []string{"NewShared", "NewCloud", "NewPrivate", "NewPrimary"}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			var names []string
+			for _, entry := range inProfile(entries, tc.profile) {
+				names = append(names, entry.Func.Name)
+			}
+			suite.Equal(tc.expected, names)
+		})
+	}
+
+	suite.Equal([]string{"local", "prod", "staging"}, Profiles(entries))
+}
@@ -0,0 +1,134 @@
+package inject
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Path is a chain of hops from one vertex to another.
+type Path[T any] []Hop[T]
+
+// String renders the path with the types linking each hop, such as
+// a -(X)-> b -(Y)-> c.
+func (p Path[T]) String() string {
+	if len(p) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(p[0].From.Key)
+	for _, hop := range p {
+		fmt.Fprintf(&b, " -(%s)-> %s", strings.Join(hop.Attr.Types, ", "), hop.To.Key)
+	}
+
+	return b.String()
+}
+
+// Ancestors returns the vertices the vertex transitively depends on, sorted by
+// key, such as every component a constructor needs.
+func (g *Graph[T]) Ancestors(key string) []*Vertex[T] {
+	return g.sorted(g.closure([]string{key}, g.incoming))
+}
+
+// Descendants returns the vertices transitively depending on the vertex,
+// sorted by key, such as every invoke affected by a broken constructor.
+func (g *Graph[T]) Descendants(key string) []*Vertex[T] {
+	return g.sorted(g.closure([]string{key}, g.edges))
+}
+
+// Reachable reports whether there is a path from one vertex to another. A path
+// takes at least one edge, so a vertex only reaches itself through a cycle.
+func (g *Graph[T]) Reachable(fromKey, toKey string) bool {
+	_, ok := g.closure([]string{fromKey}, g.edges)[toKey]
+	return ok
+}
+
+// ShortestPath returns the path with the fewest hops from one vertex to
+// another, telling why the first one is needed by the other. Among paths of
+// the same length, the one through the smallest keys is taken. As with
+// Reachable, a path takes at least one edge, so from a vertex to itself it is
+// the shortest cycle through the vertex. It reports false when there is no
+// such path.
+func (g *Graph[T]) ShortestPath(fromKey, toKey string) (Path[T], bool) {
+	if _, ok := g.vertices[fromKey]; !ok {
+		return nil, false
+	}
+
+	parent := map[string]string{fromKey: ""}
+	queue := []string{fromKey}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		for _, v := range g.edges[key] {
+			if v.Key == toKey {
+				return append(g.path(parent, fromKey, key), Hop[T]{
+					From: g.vertices[key],
+					To:   v,
+					Attr: g.attrs[key][toKey],
+				}), true
+			}
+
+			if _, ok := parent[v.Key]; ok {
+				continue
+			}
+			parent[v.Key] = key
+			queue = append(queue, v.Key)
+		}
+	}
+
+	return nil, false
+}
+
+// path builds the chain from one vertex to another, following the parents
+// found by a breadth-first search.
+func (g *Graph[T]) path(parent map[string]string, fromKey, toKey string) Path[T] {
+	keys := []string{toKey}
+	for key := toKey; key != fromKey; key = parent[key] {
+		keys = append([]string{parent[key]}, keys...)
+	}
+
+	var path Path[T]
+	for i := 0; i < len(keys)-1; i++ {
+		path = append(path, Hop[T]{
+			From: g.vertices[keys[i]],
+			To:   g.vertices[keys[i+1]],
+			Attr: g.attrs[keys[i]][keys[i+1]],
+		})
+	}
+
+	return path
+}
+
+// closure returns the keys of the vertices reached from the given ones by
+// following the adjacency lists, either the edges or the reversed edges,
+// without the given vertices unless they are reached again.
+func (g *Graph[T]) closure(keys []string, adjacency map[string][]*Vertex[T]) map[string]struct{} {
+	reached := make(map[string]struct{})
+	queue := append([]string{}, keys...)
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		for _, v := range adjacency[key] {
+			if _, ok := reached[v.Key]; !ok {
+				reached[v.Key] = struct{}{}
+				queue = append(queue, v.Key)
+			}
+		}
+	}
+
+	return reached
+}
+
+// sorted returns the vertices with the keys, sorted by key.
+func (g *Graph[T]) sorted(keys map[string]struct{}) []*Vertex[T] {
+	var vertices []*Vertex[T]
+	for _, key := range g.sortedKeys() {
+		if _, ok := keys[key]; ok {
+			vertices = append(vertices, g.vertices[key])
+		}
+	}
+
+	return vertices
+}
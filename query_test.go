@@ -0,0 +1,115 @@
+package inject
+
+import (
+	"context"
+	"github.com/americanas-go/annotation"
+	"gopkg.in/yaml.v3"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueryTestSuite struct {
+	suite.Suite
+	graph *Graph[string]
+}
+
+func TestQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(QueryTestSuite))
+}
+
+// SetupTest builds the graph a -> b -> d, a -> c -> d, d -> e, x -> e.
+func (suite *QueryTestSuite) SetupTest() {
+	suite.graph = newGraph([][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}, {"d", "e"}, {"x", "e"}})
+}
+
+func (suite *QueryTestSuite) TestAncestorsAndDescendants() {
+	testCases := []struct {
+		name        string
+		key         string
+		ancestors   []string
+		descendants []string
+	}{
+		{"Source", "a", nil, []string{"b", "c", "d", "e"}},
+		{"Middle", "d", []string{"a", "b", "c"}, []string{"e"}},
+		{"Sink", "e", []string{"a", "b", "c", "d", "x"}, nil},
+		{"Missing Vertex", "z", nil, nil},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.Equal(tc.ancestors, keysOf(suite.graph.Ancestors(tc.key)))
+			suite.Equal(tc.descendants, keysOf(suite.graph.Descendants(tc.key)))
+		})
+	}
+}
+
+func (suite *QueryTestSuite) TestReachable() {
+	suite.True(suite.graph.Reachable("a", "e"))
+	suite.True(suite.graph.Reachable("x", "e"))
+	suite.False(suite.graph.Reachable("e", "a"))
+	suite.False(suite.graph.Reachable("x", "d"))
+	suite.False(suite.graph.Reachable("a", "a"))
+	suite.True(newGraph([][2]string{{"a", "b"}, {"b", "a"}}).Reachable("a", "a"))
+	suite.True(newGraph([][2]string{{"a", "a"}}).Reachable("a", "a"))
+}
+
+func (suite *QueryTestSuite) TestShortestPath() {
+	testCases := []struct {
+		name     string
+		from, to string
+		expected string
+		found    bool
+	}{
+		{"Through The Smallest Keys", "a", "e", "a -(ab)-> b -(bd)-> d -(de)-> e", true},
+		{"Single Hop", "x", "e", "x -(xe)-> e", true},
+		{"Same Vertex", "a", "a", "", false},
+		{"Against The Edges", "e", "a", "", false},
+		{"Missing Vertex", "z", "a", "", false},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			path, found := suite.graph.ShortestPath(tc.from, tc.to)
+			suite.Equal(tc.found, found)
+			suite.Equal(tc.expected, path.String())
+		})
+	}
+}
+
+func (suite *QueryTestSuite) TestShortestPathToItself() {
+	testCases := []struct {
+		name     string
+		edges    [][2]string
+		expected string
+	}{
+		{"Self Loop", [][2]string{{"a", "a"}, {"a", "b"}}, "a -(aa)-> a"},
+		{"Cycle", [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"b", "a"}}, "a -(ab)-> b -(ba)-> a"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			path, found := newGraph(tc.edges).ShortestPath("a", "a")
+			suite.True(found)
+			suite.Equal(tc.expected, path.String())
+		})
+	}
+}
+
+func (suite *QueryTestSuite) TestWhy() {
+	data, err := os.ReadFile("testdata/inject/mkgraph/33_dependency_success.yaml")
+	suite.Require().NoError(err)
+
+	var entries []annotation.Entry
+	suite.Require().NoError(yaml.Unmarshal(data, &entries))
+
+	graph, err := NewGraphFromEntries(context.Background(), entries)
+	suite.Require().NoError(err)
+
+	path, found := graph.ShortestPath("github.com/acme/app/app_NewConfig", "github.com/acme/app/app_Run")
+	suite.True(found)
+	suite.Equal("github.com/acme/app/app_NewConfig -(*app.Config_default)-> github.com/acme/app/app_NewClient -(*app.Client_default)-> github.com/acme/app/app_Run", path.String())
+
+	suite.Equal([]string{"github.com/acme/app/app_NewClient", "github.com/acme/app/app_Run"}, keysOf(graph.Descendants("github.com/acme/app/app_NewMetrics")))
+}
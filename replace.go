@@ -0,0 +1,152 @@
+package inject
+
+import (
+	"context"
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"strings"
+)
+
+// Replacement swaps the provider of a type for a fake declared in a test file.
+type Replacement struct {
+	Component
+	Target   string               // identity of the replaced type.
+	Provider *Vertex[Component]   // vertex providing the replaced type.
+	Deps     []*Vertex[Component] // vertices providing the parameters of a replacement function.
+}
+
+// Overlay is a test-only set of replacements applied on top of a graph.
+type Overlay struct {
+	Graph        *Graph[Component]
+	Replacements []Replacement
+}
+
+// NewOverlayFromEntries builds the overlay of the entries collected from test
+// files on top of the graph. Vars replace the value of their target, while
+// functions replace it by the value they return.
+func NewOverlayFromEntries(ctx context.Context, graph *Graph[Component], entries []annotation.Entry, opts ...GraphOption) (*Overlay, error) {
+	options, err := newGraphOptions(entries, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay := &Overlay{Graph: graph}
+
+	replaced := make(map[string]string)
+
+	for _, entry := range inProfile(entries, options.profile) {
+		for _, ann := range entry.Annotations {
+			if strings.ToUpper(ann.Name) != AnnotationTypeREPLACE.String() {
+				continue
+			}
+
+			a := Annotation{}
+			err := ann.Decode(&a)
+			if err != nil {
+				return nil, err
+			}
+
+			if !strings.HasSuffix(entry.File, "_test") {
+				return nil, errors.NotValidf("the annotation %s is only allowed in test files, found in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+			}
+
+			if a.Target == "" {
+				return nil, errors.NotValidf("the target parameter is required on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+			}
+
+			if a.Flatten || a.Soft || a.Optional || a.Private || a.Primary || a.As != "" {
+				return nil, errors.NotValidf("only the target, name and group parameters are allowed on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+			}
+
+			if !isValue(entry) && len(values(entry)) != 1 {
+				return nil, errors.NotValidf("the replacement must return only the target %s, found on the annotation %s in the entry %s.%s", a.Target, ann.Name, entry.Path, entry.Func.Name)
+			}
+
+			id, err := options.id(entry, a.Target, a)
+			if err != nil {
+				return nil, err
+			}
+
+			if other, ok := replaced[id]; ok {
+				return nil, errors.Conflictf("the type %s is replaced by both %s and %s", id, other, gid(entry))
+			}
+			replaced[id] = gid(entry)
+
+			provider := providerOf(graph, id)
+			if provider == nil {
+				return nil, errors.NotFoundf("provider not found for the target %s of the replacement %s.%s", id, entry.Path, entry.Func.Name)
+			}
+
+			replacement := Replacement{
+				Component: Component{Entry: entry, An: a},
+				Target:    id,
+				Provider:  provider,
+			}
+
+			if !isValue(entry) {
+				replacement.Deps, err = replacementDeps(graph, options, entry)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			overlay.Replacements = append(overlay.Replacements, replacement)
+		}
+	}
+
+	return overlay, nil
+}
+
+// replacementDeps returns the vertices providing the parameters of a
+// replacement function.
+func replacementDeps(graph *Graph[Component], options *graphOptions, entry annotation.Entry) ([]*Vertex[Component], error) {
+	var deps []*Vertex[Component]
+	for i, param := range entry.Func.Parameters {
+		a, _, err := injectAt(entry, i)
+		if err != nil {
+			return nil, err
+		}
+
+		tp := param.Type
+		if a.Group != "" {
+			tp = strings.TrimPrefix(tp, "[]")
+		}
+
+		id, err := options.id(entry, tp, a)
+		if err != nil {
+			return nil, err
+		}
+		provider := providerOf(graph, id)
+		if provider == nil {
+			if a.Group != "" || a.Optional {
+				continue
+			}
+			return nil, errors.NotFoundf("provider not found for %s in the replacement %s.%s", id, entry.Path, entry.Func.Name)
+		}
+
+		deps = append(deps, provider)
+	}
+
+	return deps, nil
+}
+
+// providerOf returns the vertex providing the identity, or nil when none does.
+func providerOf(graph *Graph[Component], id string) *Vertex[Component] {
+	for _, key := range graph.sortedKeys() {
+		vertex := graph.vertices[key]
+		for _, provided := range vertex.Value.Provides {
+			if provided == id {
+				return vertex
+			}
+		}
+	}
+
+	return nil
+}
+
+// isValue reports whether the entry describes a var or const instead of a
+// function, as collected by CollectValues and CollectTestEntries.
+func isValue(entry annotation.Entry) bool {
+	results := entry.Func.Results
+	return len(entry.Func.Parameters) == 0 && len(results) == 1 && results[0].Name == entry.Func.Name
+}
@@ -0,0 +1,108 @@
+package inject
+
+import (
+	"context"
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OverlayTestSuite struct {
+	suite.Suite
+	graph *Graph[Component]
+}
+
+func TestOverlayTestSuite(t *testing.T) {
+	suite.Run(t, new(OverlayTestSuite))
+}
+
+func (suite *OverlayTestSuite) SetupSuite() {
+	data, err := os.ReadFile(filepath.Join("testdata/inject/mkgraph", "11_method_success.yaml"))
+	suite.Require().NoError(err)
+
+	var entries []annotation.Entry
+	suite.Require().NoError(yaml.Unmarshal(data, &entries))
+
+	suite.graph, err = NewGraphFromEntries(context.Background(), entries)
+	suite.Require().NoError(err)
+}
+
+func replaceEntry(name string, file string, params []annotation.Type, results []annotation.Type, annons ...annotation.Annotation) annotation.Entry {
+	return annotation.Entry{
+		Module:      "github.com/acme/app",
+		File:        file,
+		Path:        "github.com/acme/app/client",
+		Package:     "client",
+		Func:        annotation.Func{Name: name, Parameters: params, Results: results},
+		Annotations: annons,
+	}
+}
+
+func (suite *OverlayTestSuite) TestNewOverlayFromEntries() {
+	fakeClient := replaceEntry("FakeClient", "client_test", nil, []annotation.Type{{Name: "FakeClient", Type: "*Client"}},
+		annotation.Annotation{Name: "Replace", Map: map[string]interface{}{"target": "*Client"}})
+	fakeConfig := replaceEntry("newFakeConfig", "client_test", []annotation.Type{{Name: "f", Type: "*Factory"}}, []annotation.Type{{Type: "Config"}},
+		annotation.Annotation{Name: "Replace", Map: map[string]interface{}{"target": "Config", "name": "cfg"}})
+
+	testCases := []struct {
+		name      string
+		entries   []annotation.Entry
+		expectErr bool
+		errType   error
+	}{
+		{
+			name:    "Value And Function",
+			entries: []annotation.Entry{fakeClient, fakeConfig},
+		},
+		{
+			name:      "Outside Test Files",
+			entries:   []annotation.Entry{replaceEntry("FakeClient", "client", nil, fakeClient.Func.Results, fakeClient.Annotations...)},
+			expectErr: true,
+			errType:   errors.NotValidf("tipo de erro esperado"),
+		},
+		{
+			name: "Target Not Found",
+			entries: []annotation.Entry{replaceEntry("FakeServer", "client_test", nil, []annotation.Type{{Name: "FakeServer", Type: "*Server"}},
+				annotation.Annotation{Name: "Replace", Map: map[string]interface{}{"target": "*Server"}})},
+			expectErr: true,
+			errType:   errors.NotFoundf("tipo de erro esperado"),
+		},
+		{
+			name:      "Replaced Twice",
+			entries:   []annotation.Entry{fakeClient, replaceEntry("OtherClient", "client_test", nil, []annotation.Type{{Name: "OtherClient", Type: "*Client"}}, fakeClient.Annotations...)},
+			expectErr: true,
+			errType:   errors.Conflictf("tipo de erro esperado"),
+		},
+		{
+			name: "Dependency Not Found",
+			entries: []annotation.Entry{replaceEntry("newFakeClient", "client_test", []annotation.Type{{Name: "s", Type: "*Server"}}, []annotation.Type{{Type: "*Client"}},
+				fakeClient.Annotations...)},
+			expectErr: true,
+			errType:   errors.NotFoundf("tipo de erro esperado"),
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			overlay, err := NewOverlayFromEntries(context.Background(), suite.graph, tc.entries)
+
+			if tc.expectErr {
+				suite.Error(err)
+				suite.IsType(tc.errType, err)
+				return
+			}
+
+			suite.Require().NoError(err)
+			suite.Require().Len(overlay.Replacements, 2)
+			suite.Equal("github.com/acme/app/client_Factory_NewClient", overlay.Replacements[0].Provider.Key)
+			suite.Equal("github.com/acme/app/client_NewConfig", overlay.Replacements[1].Provider.Key)
+			suite.Require().Len(overlay.Replacements[1].Deps, 1)
+			suite.Equal("github.com/acme/app/client_NewFactory", overlay.Replacements[1].Deps[0].Key)
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package inject
+
+import (
+	"fmt"
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/packages"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TypeResolver resolves the types as written in a file of a package. Types
+// may also be qualified by the full import path of their package, such as
+// github.com/acme/app/store.DB.
+type TypeResolver interface {
+	// Resolve returns the identity of the type. Two types share an identity
+	// when they refer to the same type.
+	Resolve(path string, pkg string, file string, tp string) (string, error)
+	// Qualify returns the type with every named type qualified by the import
+	// path of its package, so it can be referenced from any package.
+	Qualify(path string, pkg string, file string, tp string) (string, error)
+}
+
+// nameResolver qualifies the types by the name of their package. It is the
+// default resolver, since it does not require loading the packages, but it
+// can't tell apart packages sharing a name nor follow aliases, and it only
+// knows the import paths of the packages of the entries.
+type nameResolver struct {
+	paths map[string][]string // import paths of the packages of the entries, by name.
+}
+
+func newNameResolver(entries []annotation.Entry) nameResolver {
+	r := nameResolver{paths: make(map[string][]string)}
+
+	seen := make(map[string]struct{})
+	for _, entry := range entries {
+		if _, ok := seen[entry.Path]; ok || entry.Package == "" {
+			continue
+		}
+		seen[entry.Path] = struct{}{}
+		r.paths[entry.Package] = append(r.paths[entry.Package], entry.Path)
+	}
+
+	for _, paths := range r.paths {
+		sort.Strings(paths)
+	}
+
+	return r
+}
+
+func (nameResolver) Resolve(path string, pkg string, file string, tp string) (string, error) {
+	// the import paths are dropped, keeping the package names
+	tp = dropImportPaths(tp)
+
+	expr, err := parser.ParseExpr(tp)
+	if err != nil {
+		return "", errors.NotValidf("the type %s is invalid: %v", tp, err)
+	}
+
+	expr = rewriteNames(expr, func(name string) ast.Expr {
+		if strings.Contains(name, ".") || types.Universe.Lookup(name) != nil {
+			return nil
+		}
+		return &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent(name)}
+	})
+
+	return types.ExprString(expr), nil
+}
+
+func (r nameResolver) Qualify(path string, pkg string, file string, tp string) (string, error) {
+	replaced, imports := splitImportPaths(tp)
+
+	expr, err := parser.ParseExpr(replaced)
+	if err != nil {
+		return "", errors.NotValidf("the type %s is invalid: %v", tp, err)
+	}
+
+	var qerr error
+	expr = rewriteNames(expr, func(name string) ast.Expr {
+		if qerr != nil || types.Universe.Lookup(name) != nil {
+			return nil
+		}
+
+		idx := strings.LastIndex(name, ".")
+		if idx < 0 {
+			return ast.NewIdent(path + "." + name)
+		}
+
+		qualifier, sel := name[:idx], name[idx+1:]
+		if imported, ok := imports[qualifier]; ok {
+			return ast.NewIdent(imported + "." + sel)
+		}
+
+		switch paths := r.paths[qualifier]; len(paths) {
+		case 0:
+			qerr = errors.NotFoundf("package %s of the type %s not found in %s, use its full import path", qualifier, tp, path)
+		case 1:
+			return ast.NewIdent(paths[0] + "." + sel)
+		default:
+			qerr = errors.NotValidf("package %s of the type %s is ambiguous between %s in %s, use its full import path", qualifier, tp, strings.Join(paths, ", "), path)
+		}
+
+		return nil
+	})
+	if qerr != nil {
+		return "", qerr
+	}
+
+	return types.ExprString(expr), nil
+}
+
+// qualifiedNameRegexp matches the names qualified by an import path, capturing
+// the path and the name.
+var qualifiedNameRegexp = regexp.MustCompile(`((?:[\w.\-~]+/)+[\w.\-~]+)\.(\w+)`)
+
+// splitImportPaths replaces the import paths qualifying the names of a type by
+// identifiers, so it can be parsed, and returns the paths they stand for.
+func splitImportPaths(tp string) (string, map[string]string) {
+	imports := make(map[string]string)
+	aliases := make(map[string]string)
+
+	replaced := qualifiedNameRegexp.ReplaceAllStringFunc(tp, func(name string) string {
+		match := qualifiedNameRegexp.FindStringSubmatch(name)
+		alias, ok := aliases[match[1]]
+		if !ok {
+			alias = fmt.Sprintf("_path%d", len(aliases))
+			aliases[match[1]] = alias
+			imports[alias] = match[1]
+		}
+
+		return alias + "." + match[2]
+	})
+
+	return replaced, imports
+}
+
+// PackagesResolver resolves the types with go/types, in the scope of the file
+// declaring them, so import aliases, dot imports and type aliases are
+// followed, and types are qualified by their full import path.
+type PackagesResolver struct {
+	fset *token.FileSet
+	pkgs map[string]*packages.Package
+}
+
+// NewPackagesResolver loads the packages matching the patterns from dir,
+// including their test files.
+func NewPackagesResolver(dir string, patterns ...string) (*PackagesResolver, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports,
+		Dir:   dir,
+		Fset:  fset,
+		Tests: true,
+	}
+
+	loaded, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &PackagesResolver{fset: fset, pkgs: make(map[string]*packages.Package)}
+	packages.Visit(loaded, nil, func(pkg *packages.Package) {
+		if pkg.Types == nil {
+			return
+		}
+
+		// the test variant of a package also has its production files
+		key := pkg.Types.Path()
+		if other, ok := resolver.pkgs[key]; !ok || len(pkg.Syntax) > len(other.Syntax) {
+			resolver.pkgs[key] = pkg
+		}
+	})
+
+	return resolver, nil
+}
+
+func (r *PackagesResolver) Resolve(path string, pkg string, file string, tp string) (string, error) {
+	p, ok := r.pkgs[path]
+	if !ok && strings.HasSuffix(pkg, "_test") {
+		p, ok = r.pkgs[path+"_test"]
+	}
+	if !ok {
+		return "", errors.NotFoundf("package %s not loaded to resolve the type %s", path, tp)
+	}
+
+	tv, err := r.eval(p, file, tp)
+	if err != nil {
+		return "", err
+	}
+
+	if !tv.IsType() {
+		return "", errors.NotValidf("the expression %s is not a type in %s", tp, path)
+	}
+
+	return types.TypeString(unalias(tv.Type), nil), nil
+}
+
+// Qualify returns the same as Resolve, since the identities are already
+// qualified by the import paths.
+func (r *PackagesResolver) Qualify(path string, pkg string, file string, tp string) (string, error) {
+	return r.Resolve(path, pkg, file, tp)
+}
+
+// eval evaluates the type in the scope of the named file of the package. The
+// names qualified by an import path are looked up in the loaded packages.
+func (r *PackagesResolver) eval(p *packages.Package, file string, tp string) (types.TypeAndValue, error) {
+	f := r.file(p, file)
+
+	replaced, imports := splitImportPaths(tp)
+	if len(imports) == 0 {
+		pos := token.NoPos
+		if f != nil {
+			pos = f.Name.Pos()
+		}
+
+		tv, err := types.Eval(r.fset, p.Types, pos, tp)
+		if err != nil {
+			return tv, errors.NotValidf("the type %s can't be resolved in %s: %v", tp, p.Types.Path(), err)
+		}
+		return tv, nil
+	}
+
+	// the package and file scopes are copied into a scope importing the paths
+	scoped := types.NewPackage(p.Types.Path(), p.Types.Name())
+	for _, scope := range []*types.Scope{p.Types.Scope(), p.TypesInfo.Scopes[f]} {
+		if scope == nil {
+			continue
+		}
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			// package names are bound to the package importing them
+			if pkgName, ok := obj.(*types.PkgName); ok {
+				obj = types.NewPkgName(token.NoPos, scoped, name, pkgName.Imported())
+			}
+			scoped.Scope().Insert(obj)
+		}
+	}
+
+	for alias, path := range imports {
+		imported, ok := r.pkgs[path]
+		if !ok {
+			return types.TypeAndValue{}, errors.NotFoundf("package %s not loaded to resolve the type %s", path, tp)
+		}
+		scoped.Scope().Insert(types.NewPkgName(token.NoPos, scoped, alias, imported.Types))
+	}
+
+	tv, err := types.Eval(r.fset, scoped, token.NoPos, replaced)
+	if err != nil {
+		return tv, errors.NotValidf("the type %s can't be resolved in %s: %v", tp, p.Types.Path(), err)
+	}
+
+	return tv, nil
+}
+
+// file returns the syntax of the named file of the package, without its
+// extension, or nil when the package has no such file.
+func (r *PackagesResolver) file(p *packages.Package, name string) *ast.File {
+	if name == "" {
+		return nil
+	}
+
+	for _, f := range p.Syntax {
+		if strings.TrimSuffix(filepath.Base(r.fset.File(f.Pos()).Name()), ".go") == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// unalias replaces the aliases in a type by the types they denote.
+func unalias(t types.Type) types.Type {
+	switch tp := types.Unalias(t).(type) {
+	case *types.Pointer:
+		return types.NewPointer(unalias(tp.Elem()))
+	case *types.Slice:
+		return types.NewSlice(unalias(tp.Elem()))
+	case *types.Array:
+		return types.NewArray(unalias(tp.Elem()), tp.Len())
+	case *types.Map:
+		return types.NewMap(unalias(tp.Key()), unalias(tp.Elem()))
+	case *types.Chan:
+		return types.NewChan(tp.Dir(), unalias(tp.Elem()))
+	case *types.Named:
+		if tp.TypeArgs().Len() == 0 {
+			return tp
+		}
+
+		var args []types.Type
+		for i := 0; i < tp.TypeArgs().Len(); i++ {
+			args = append(args, unalias(tp.TypeArgs().At(i)))
+		}
+
+		inst, err := types.Instantiate(nil, tp.Origin(), args, false)
+		if err != nil {
+			return tp
+		}
+		return inst
+	default:
+		return tp
+	}
+}
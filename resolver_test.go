@@ -0,0 +1,205 @@
+package inject
+
+import (
+	"context"
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ResolverTestSuite struct {
+	suite.Suite
+	resolver *PackagesResolver
+}
+
+func TestResolverTestSuite(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	suite.Run(t, new(ResolverTestSuite))
+}
+
+func (suite *ResolverTestSuite) SetupSuite() {
+	var err error
+	suite.resolver, err = NewPackagesResolver("testdata/inject/resolve")
+	suite.Require().NoError(err)
+}
+
+func (suite *ResolverTestSuite) TestResolve() {
+	testCases := []struct {
+		name     string
+		file     string
+		tp       string
+		expected string
+	}{
+		{"Local Type", "app", "*Service", "*github.com/acme/resolve/app.Service"},
+		{"Import Alias", "app", "*store.Client", "*github.com/acme/resolve/db.Client"},
+		{"Same Package Name", "app", "legacy.Client", "github.com/acme/resolve/legacy/db.Client"},
+		{"Dot Import", "dot", "Client", "github.com/acme/resolve/db.Client"},
+		{"Type Alias", "dot", "[]*Conn", "[]*github.com/acme/resolve/db.Client"},
+		{"Map Of Channels", "app", "map[string]chan store.Client", "map[string]chan github.com/acme/resolve/db.Client"},
+		{"Func Type", "app", "func(*Service) error", "func(*github.com/acme/resolve/app.Service) error"},
+		{"Predeclared Type", "app", "int", "int"},
+		{"Generic Instance", "app", "store.Store[*Service]", "github.com/acme/resolve/db.Store[*github.com/acme/resolve/app.Service]"},
+		{"Generic Instance Of Alias", "dot", "Store[Conn]", "github.com/acme/resolve/db.Store[github.com/acme/resolve/db.Client]"},
+		{"Import Path", "app", "*github.com/acme/resolve/legacy/db.Client", "*github.com/acme/resolve/legacy/db.Client"},
+		{"Import Path Of Alias", "", "[]github.com/acme/resolve/db.Conn", "[]github.com/acme/resolve/db.Client"},
+		{"Import Path In Generic Instance", "app", "store.Store[github.com/acme/resolve/legacy/db.Client]", "github.com/acme/resolve/db.Store[github.com/acme/resolve/legacy/db.Client]"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			id, err := suite.resolver.Resolve("github.com/acme/resolve/app", "app", tc.file, tc.tp)
+			suite.NoError(err)
+			suite.Equal(tc.expected, id)
+		})
+	}
+}
+
+func (suite *ResolverTestSuite) TestResolveErrors() {
+	_, err := suite.resolver.Resolve("github.com/acme/resolve/unknown", "unknown", "", "Client")
+	suite.Error(err)
+
+	_, err = suite.resolver.Resolve("github.com/acme/resolve/app", "app", "app", "store.Unknown")
+	suite.Error(err)
+
+	_, err = suite.resolver.Resolve("github.com/acme/resolve/app", "app", "app", "github.com/acme/resolve/unknown.Client")
+	suite.IsType(errors.NotFoundf(""), err)
+}
+
+func (suite *ResolverTestSuite) TestQualify() {
+	tp, err := suite.resolver.Qualify("github.com/acme/resolve/app", "app", "app", "map[string]*store.Client")
+	suite.NoError(err)
+	suite.Equal("map[string]*github.com/acme/resolve/db.Client", tp)
+}
+
+func (suite *ResolverTestSuite) TestNameResolver() {
+	testCases := []struct {
+		tp       string
+		expected string
+	}{
+		{"*Client", "*db.Client"},
+		{"other.Client", "other.Client"},
+		{"error", "error"},
+		{"[]*Client", "[]*db.Client"},
+		{"Store[Client, other.ID]", "db.Store[db.Client, other.ID]"},
+		{"map[string]func(c Client) error", "map[string]func(c db.Client) error"},
+		{"*github.com/acme/app/other.Client", "*other.Client"},
+		{"map[string]gopkg.in/yaml.v3.Node", "map[string]yaml.Node"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.tp, func() {
+			id, err := nameResolver{}.Resolve("github.com/acme/app/db", "db", "db", tc.tp)
+			suite.NoError(err)
+			suite.Equal(tc.expected, id)
+		})
+	}
+}
+
+func (suite *ResolverTestSuite) TestNameResolverQualify() {
+	resolver := newNameResolver([]annotation.Entry{
+		{Path: "github.com/acme/app/db", Package: "db"},
+		{Path: "github.com/acme/app/user", Package: "user"},
+		{Path: "github.com/acme/app/cache", Package: "cache"},
+		{Path: "github.com/acme/app/legacy/cache", Package: "cache"},
+	})
+
+	testCases := []struct {
+		tp       string
+		expected string
+	}{
+		{"*Client", "*github.com/acme/app/db.Client"},
+		{"user.User", "github.com/acme/app/user.User"},
+		{"Store[user.User, error]", "github.com/acme/app/db.Store[github.com/acme/app/user.User, error]"},
+		{"github.com/acme/app/order.Order", "github.com/acme/app/order.Order"},
+		{"github.com/acme/app/legacy/cache.Cache", "github.com/acme/app/legacy/cache.Cache"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.tp, func() {
+			tp, err := resolver.Qualify("github.com/acme/app/db", "db", "db", tc.tp)
+			suite.NoError(err)
+			suite.Equal(tc.expected, tp)
+		})
+	}
+
+	_, err := resolver.Qualify("github.com/acme/app/db", "db", "db", "order.Order")
+	suite.IsType(errors.NotFoundf(""), err)
+
+	_, err = resolver.Qualify("github.com/acme/app/db", "db", "db", "cache.Cache")
+	suite.IsType(errors.NotValidf(""), err)
+}
+
+func (suite *ResolverTestSuite) TestGraphWithResolver() {
+	provide := annotation.Annotation{Name: "Provide", Map: map[string]interface{}{}}
+	inject := annotation.Annotation{Name: "Inject", Map: map[string]interface{}{}}
+
+	entries := []annotation.Entry{
+		{Module: "github.com/acme/resolve", File: "db", Path: "github.com/acme/resolve/legacy/db", Package: "db",
+			Func:        annotation.Func{Name: "NewClient", Results: []annotation.Type{{Type: "*Client"}}},
+			Annotations: []annotation.Annotation{provide}},
+		{Module: "github.com/acme/resolve", File: "db", Path: "github.com/acme/resolve/db", Package: "db",
+			Func:        annotation.Func{Name: "NewConn", Results: []annotation.Type{{Type: "*Conn"}}},
+			Annotations: []annotation.Annotation{provide}},
+		{Module: "github.com/acme/resolve", File: "app", Path: "github.com/acme/resolve/app", Package: "app",
+			Func:        annotation.Func{Name: "NewService", Parameters: []annotation.Type{{Name: "c", Type: "*store.Client"}}, Results: []annotation.Type{{Type: "*Service"}}},
+			Annotations: []annotation.Annotation{inject, provide}},
+	}
+
+	graph, err := NewGraphFromEntries(context.Background(), entries, WithResolver(suite.resolver))
+	suite.Require().NoError(err)
+
+	var keys []string
+	for _, v := range graph.vertices["github.com/acme/resolve/app_NewService"].Incoming() {
+		keys = append(keys, v.Key)
+	}
+	suite.Equal([]string{"github.com/acme/resolve/db_NewConn"}, keys)
+}
+
+func (suite *ResolverTestSuite) TestGraphWithImportPaths() {
+	entries := []annotation.Entry{
+		{Module: "github.com/acme/resolve", File: "app", Path: "github.com/acme/resolve/app", Package: "app",
+			Func: annotation.Func{Name: "NewService", Results: []annotation.Type{{Type: "*Service"}}},
+			Annotations: []annotation.Annotation{{Name: "Provide", Map: map[string]interface{}{
+				"as": "github.com/acme/resolve/legacy/db.Client"}}}},
+		{Module: "github.com/acme/resolve", File: "app", Path: "github.com/acme/resolve/app", Package: "app",
+			Func: annotation.Func{Name: "NewStore", Results: []annotation.Type{{Type: "*Service"}}},
+			Annotations: []annotation.Annotation{{Name: "Provide", Map: map[string]interface{}{
+				"as": "store.Client"}}}},
+		{Module: "github.com/acme/resolve", File: "app", Path: "github.com/acme/resolve/app", Package: "app",
+			Func:        annotation.Func{Name: "Run", Parameters: []annotation.Type{{Name: "c", Type: "legacy.Client"}, {Name: "s", Type: "store.Client"}}},
+			Annotations: []annotation.Annotation{{Name: "Invoke", Map: map[string]interface{}{}}}},
+	}
+
+	graph, err := NewGraphFromEntries(context.Background(), entries, WithResolver(suite.resolver))
+	suite.Require().NoError(err)
+
+	var keys []string
+	for _, v := range graph.vertices["github.com/acme/resolve/app_Run"].Incoming() {
+		keys = append(keys, v.Key)
+	}
+	suite.Equal([]string{"github.com/acme/resolve/app_NewService", "github.com/acme/resolve/app_NewStore"}, keys)
+
+	suite.Equal(map[string]string{"github.com/acme/resolve/legacy/db.Client": "github.com/acme/resolve/legacy/db.Client"},
+		graph.vertices["github.com/acme/resolve/app_NewService"].Value.Types)
+	suite.Equal(map[string]string{"store.Client": "github.com/acme/resolve/db.Client"},
+		graph.vertices["github.com/acme/resolve/app_NewStore"].Value.Types)
+}
+
+func (suite *ResolverTestSuite) TestGeneratorAsWithResolver() {
+	// the package of the interface has no annotated component
+	entry := annotation.Entry{Module: "github.com/acme/resolve", File: "app", Path: "github.com/acme/resolve/app", Package: "app",
+		Func: annotation.Func{Name: "NewService", Results: []annotation.Type{{Type: "*Service"}}},
+		Annotations: []annotation.Annotation{{Name: "Provide", Map: map[string]interface{}{
+			"as": "store.Client"}}}}
+
+	graph, err := NewGraphFromEntries(context.Background(), []annotation.Entry{entry}, WithResolver(suite.resolver))
+	suite.Require().NoError(err)
+
+	alias := generateAlias("github.com/acme/resolve/db")
+	as, _, imports, err := NewGenerator("github.com/acme/resolve", graph).getAs(graph.vertices[gid(entry)].Value, generateAlias(entry.Path))
+	suite.NoError(err)
+	suite.Equal([]string{"new(" + alias + ".Client)"}, as)
+	suite.Equal([]ImportData{{Alias: alias, Path: "github.com/acme/resolve/db"}}, imports)
+}
@@ -2,10 +2,13 @@
 package inject
 
 import (
+	"fmt"
+	"github.com/americanas-go/annotation"
+	"github.com/americanas-go/errors"
 	"strings"
 )
 
-// ENUM(MODULE,PROVIDE,INJECT,INVOKE)
+// ENUM(MODULE,PROVIDE,INJECT,INVOKE,DECORATE,SUPPLY,ONSTART,ONSTOP,PRIVATE,REPLACE,PRIMARY)
 type AnnotationType int
 
 // ENUM(MODULE,PATH,PACKAGE,FUNC)
@@ -15,9 +18,22 @@ type ModuleAttr int
 type AnnotationIDType int
 
 type Annotation struct {
-	Index *int
-	Name  string
-	Group string
+	Index    *int
+	Param    string
+	Name     string
+	Group    string
+	Flatten  bool
+	Soft     bool
+	Optional bool
+	As       string
+	Self     bool
+	Private  bool
+	Primary  bool
+	Scope    string
+	Type     string
+	Target   string
+	Instance string
+	Profile  string
 }
 
 func (a *Annotation) ID() string {
@@ -33,3 +49,126 @@ func (a *Annotation) ID() string {
 	return strings.ToLower(
 		strings.Join(fields, "_"))
 }
+
+func (a *Annotation) Tag() string {
+	var tags []string
+	if a.Name != "" {
+		tags = append(tags, fmt.Sprintf(`name:"%s"`, a.Name))
+	} else if a.Group != "" {
+		group := a.Group
+		if a.Flatten {
+			group += ",flatten"
+		}
+		if a.Soft {
+			group += ",soft"
+		}
+		tags = append(tags, fmt.Sprintf(`group:"%s"`, group))
+	}
+
+	if a.Optional {
+		tags = append(tags, `optional:"true"`)
+	}
+
+	return strings.Join(tags, " ")
+}
+
+// decodeAnnotation decodes the annotation of the entry, resolving the index of
+// provides, injects and decorators. The index may be omitted when it is
+// unambiguous, and injects may address their parameter by name instead.
+func decodeAnnotation(entry annotation.Entry, ann annotation.Annotation) (Annotation, error) {
+	a := Annotation{}
+	err := ann.Decode(&a)
+	if err != nil {
+		return a, err
+	}
+
+	annType, err := ParseAnnotationType(strings.ToUpper(ann.Name))
+	if err != nil {
+		return a, nil
+	}
+
+	switch annType {
+	case AnnotationTypeINJECT:
+		params := entry.Func.Parameters
+
+		if a.Param != "" {
+			index := -1
+			for i, param := range params {
+				if param.Name == a.Param {
+					index = i
+				}
+			}
+
+			if index < 0 {
+				return a, errors.NotValidf("the parameter %s not found on the annotation %s in the entry %s.%s, whose signature is %s", a.Param, ann.Name, entry.Path, entry.Func.Name, signature(entry))
+			}
+
+			if a.Index != nil && *a.Index != index {
+				return a, errors.NotValidf("the index %d does not match the parameter %s on the annotation %s in the entry %s.%s, whose signature is %s", *a.Index, a.Param, ann.Name, entry.Path, entry.Func.Name, signature(entry))
+			}
+
+			a.Index = &index
+		}
+
+		if a.Index == nil && len(params) == 1 {
+			a.Index = new(int)
+		}
+
+		return a, checkIndex(entry, ann, a, len(params), "the function has more than one parameter, use the param parameter instead")
+
+	case AnnotationTypePROVIDE, AnnotationTypeDECORATE:
+		if a.Param != "" {
+			return a, errors.NotValidf("the param parameter is only allowed on inject, found on the annotation %s in the entry %s.%s", ann.Name, entry.Path, entry.Func.Name)
+		}
+
+		if a.Index == nil && len(values(entry)) == 1 {
+			a.Index = new(int)
+		}
+
+		return a, checkIndex(entry, ann, a, len(entry.Func.Results), "the function has more than one result")
+	}
+
+	return a, nil
+}
+
+// checkIndex ensures that the index of the annotation is set and in range.
+func checkIndex(entry annotation.Entry, ann annotation.Annotation, a Annotation, size int, ambiguity string) error {
+	if a.Index == nil {
+		return errors.NotValidf("the index parameter is required on the annotation %s in the entry %s.%s since %s, whose signature is %s", ann.Name, entry.Path, entry.Func.Name, ambiguity, signature(entry))
+	}
+
+	if *a.Index < 0 || *a.Index >= size {
+		return errors.NotValidf("the index %d is out of range on the annotation %s in the entry %s.%s, whose signature is %s", *a.Index, ann.Name, entry.Path, entry.Func.Name, signature(entry))
+	}
+
+	return nil
+}
+
+// signature returns the declaration of the entry function, as written in Go.
+func signature(entry annotation.Entry) string {
+	var params []string
+	for _, param := range entry.Func.Parameters {
+		params = append(params, strings.TrimSpace(param.Name+" "+param.Type))
+	}
+
+	var results []string
+	for _, res := range entry.Func.Results {
+		results = append(results, strings.TrimSpace(res.Name+" "+res.Type))
+	}
+
+	sig := "func "
+	if entry.Struct != "" {
+		sig += "(" + entry.Struct + ") "
+	}
+	sig += entry.Func.Name + "(" + strings.Join(params, ", ") + ")"
+
+	if len(results) == 1 && entry.Func.Results[0].Name == "" {
+		return sig + " " + results[0]
+	}
+
+	if len(results) > 0 {
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+
+	return sig
+}
@@ -84,17 +84,38 @@ const (
 	AnnotationTypeINJECT
 	// AnnotationTypeINVOKE is a AnnotationType of type INVOKE.
 	AnnotationTypeINVOKE
+	// AnnotationTypeDECORATE is a AnnotationType of type DECORATE.
+	AnnotationTypeDECORATE
+	// AnnotationTypeSUPPLY is a AnnotationType of type SUPPLY.
+	AnnotationTypeSUPPLY
+	// AnnotationTypeONSTART is a AnnotationType of type ONSTART.
+	AnnotationTypeONSTART
+	// AnnotationTypeONSTOP is a AnnotationType of type ONSTOP.
+	AnnotationTypeONSTOP
+	// AnnotationTypePRIVATE is a AnnotationType of type PRIVATE.
+	AnnotationTypePRIVATE
+	// AnnotationTypeREPLACE is a AnnotationType of type REPLACE.
+	AnnotationTypeREPLACE
+	// AnnotationTypePRIMARY is a AnnotationType of type PRIMARY.
+	AnnotationTypePRIMARY
 )
 
 var ErrInvalidAnnotationType = errors.New("not a valid AnnotationType")
 
-const _AnnotationTypeName = "MODULEPROVIDEINJECTINVOKE"
+const _AnnotationTypeName = "MODULEPROVIDEINJECTINVOKEDECORATESUPPLYONSTARTONSTOPPRIVATEREPLACEPRIMARY"
 
 var _AnnotationTypeMap = map[AnnotationType]string{
-	AnnotationTypeMODULE:  _AnnotationTypeName[0:6],
-	AnnotationTypePROVIDE: _AnnotationTypeName[6:13],
-	AnnotationTypeINJECT:  _AnnotationTypeName[13:19],
-	AnnotationTypeINVOKE:  _AnnotationTypeName[19:25],
+	AnnotationTypeMODULE:   _AnnotationTypeName[0:6],
+	AnnotationTypePROVIDE:  _AnnotationTypeName[6:13],
+	AnnotationTypeINJECT:   _AnnotationTypeName[13:19],
+	AnnotationTypeINVOKE:   _AnnotationTypeName[19:25],
+	AnnotationTypeDECORATE: _AnnotationTypeName[25:33],
+	AnnotationTypeSUPPLY:   _AnnotationTypeName[33:39],
+	AnnotationTypeONSTART:  _AnnotationTypeName[39:46],
+	AnnotationTypeONSTOP:   _AnnotationTypeName[46:52],
+	AnnotationTypePRIVATE:  _AnnotationTypeName[52:59],
+	AnnotationTypeREPLACE:  _AnnotationTypeName[59:66],
+	AnnotationTypePRIMARY:  _AnnotationTypeName[66:73],
 }
 
 // String implements the Stringer interface.
@@ -117,6 +138,13 @@ var _AnnotationTypeValue = map[string]AnnotationType{
 	_AnnotationTypeName[6:13]:  AnnotationTypePROVIDE,
 	_AnnotationTypeName[13:19]: AnnotationTypeINJECT,
 	_AnnotationTypeName[19:25]: AnnotationTypeINVOKE,
+	_AnnotationTypeName[25:33]: AnnotationTypeDECORATE,
+	_AnnotationTypeName[33:39]: AnnotationTypeSUPPLY,
+	_AnnotationTypeName[39:46]: AnnotationTypeONSTART,
+	_AnnotationTypeName[46:52]: AnnotationTypeONSTOP,
+	_AnnotationTypeName[52:59]: AnnotationTypePRIVATE,
+	_AnnotationTypeName[59:66]: AnnotationTypeREPLACE,
+	_AnnotationTypeName[66:73]: AnnotationTypePRIMARY,
 }
 
 // ParseAnnotationType attempts to convert a string to a AnnotationType.
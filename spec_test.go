@@ -0,0 +1,102 @@
+package inject
+
+import (
+	"github.com/americanas-go/annotation"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AnnotationTestSuite struct {
+	suite.Suite
+}
+
+func TestAnnotationTestSuite(t *testing.T) {
+	suite.Run(t, new(AnnotationTestSuite))
+}
+
+func (suite *AnnotationTestSuite) TestTag() {
+	testCases := []struct {
+		name     string
+		an       Annotation
+		expected string
+	}{
+		{"Default", Annotation{}, ""},
+		{"Named", Annotation{Name: "A"}, `name:"A"`},
+		{"Grouped", Annotation{Group: "handlers"}, `group:"handlers"`},
+		{"Grouped Flatten", Annotation{Group: "handlers", Flatten: true}, `group:"handlers,flatten"`},
+		{"Grouped Soft", Annotation{Group: "handlers", Soft: true}, `group:"handlers,soft"`},
+		{"Optional", Annotation{Optional: true}, `optional:"true"`},
+		{"Named Optional", Annotation{Name: "A", Optional: true}, `name:"A" optional:"true"`},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.Equal(tc.expected, tc.an.Tag())
+		})
+	}
+}
+
+func (suite *AnnotationTestSuite) TestDecodeAnnotation() {
+	entry := annotation.Entry{
+		Path: "github.com/acme/app/user",
+		Func: annotation.Func{
+			Name:       "NewService",
+			Parameters: []annotation.Type{{Name: "repo", Type: "*Repo"}, {Name: "cfg", Type: "Config"}},
+			Results:    []annotation.Type{{Type: "*Service"}, {Type: "error"}},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		ann       annotation.Annotation
+		expected  int
+		expectErr bool
+	}{
+		{"Provide Inferred From The Single Value", annotation.Annotation{Name: "Provide", Map: map[string]interface{}{}}, 0, false},
+		{"Inject By Parameter Name", annotation.Annotation{Name: "Inject", Map: map[string]interface{}{"param": "cfg"}}, 1, false},
+		{"Inject By Matching Index And Name", annotation.Annotation{Name: "Inject", Map: map[string]interface{}{"param": "cfg", "index": 1}}, 1, false},
+		{"Inject Ambiguous", annotation.Annotation{Name: "Inject", Map: map[string]interface{}{}}, 0, true},
+		{"Inject Unknown Parameter", annotation.Annotation{Name: "Inject", Map: map[string]interface{}{"param": "db"}}, 0, true},
+		{"Inject Mismatched Index And Name", annotation.Annotation{Name: "Inject", Map: map[string]interface{}{"param": "cfg", "index": 0}}, 0, true},
+		{"Inject Out Of Range", annotation.Annotation{Name: "Inject", Map: map[string]interface{}{"index": 2}}, 0, true},
+		{"Provide Out Of Range", annotation.Annotation{Name: "Provide", Map: map[string]interface{}{"index": -1}}, 0, true},
+		{"Provide By Parameter Name", annotation.Annotation{Name: "Provide", Map: map[string]interface{}{"param": "cfg"}}, 0, true},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			a, err := decodeAnnotation(entry, tc.ann)
+			if tc.expectErr {
+				suite.Error(err)
+				return
+			}
+
+			suite.Require().NoError(err)
+			suite.Require().NotNil(a.Index)
+			suite.Equal(tc.expected, *a.Index)
+		})
+	}
+}
+
+func (suite *AnnotationTestSuite) TestSignature() {
+	testCases := []struct {
+		name     string
+		entry    annotation.Entry
+		expected string
+	}{
+		{"Function", annotation.Entry{Func: annotation.Func{Name: "NewService",
+			Parameters: []annotation.Type{{Name: "repo", Type: "*Repo"}, {Name: "cfg", Type: "Config"}},
+			Results:    []annotation.Type{{Type: "*Service"}, {Type: "error"}}}},
+			"func NewService(repo *Repo, cfg Config) (*Service, error)"},
+		{"Method", annotation.Entry{Struct: "*Factory", Func: annotation.Func{Name: "NewClient",
+			Results: []annotation.Type{{Type: "*Client"}}}},
+			"func (*Factory) NewClient() *Client"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.Equal(tc.expected, signature(tc.entry))
+		})
+	}
+}
@@ -12,55 +12,239 @@ package {{.PackageName}}
 
 import (
 	{{.Alias}} "{{.ImportPath}}"
+{{- if and .Hook .Hook.Context}}
+	"context"
+{{- end}}
 {{- range .Imports}}
 	{{.Alias}} "{{.Path}}"
+{{- end}}
+{{- range .TypeImports}}
+	{{.Alias}} "{{.Path}}"
 {{- end}}
 	"sync"
 	"go.uber.org/fx"
 )
 
-var {{.FunctionName}}Once sync.Once
+var {{.FunctionName}}Once{{.Suffix}} sync.Once
 
-func {{.FunctionName}}Module() fx.Option {
+func {{.FunctionName}}Module{{.Suffix}}() fx.Option {
 	options := fx.Options()
 
-	{{.FunctionName}}Once.Do(func() {
-{{if eq .Type "PROVIDE"}}
+	{{.FunctionName}}Once{{.Suffix}}.Do(func() {
+{{if and (or (eq .Type "PROVIDE") (eq .Type "SUPPLY")) (not .Private)}}
 	options = fx.Module("{{.FunctionName}}",
 {{else}}
 	options = fx.Options(
 {{end}}
 {{- range .Modules}}
-		{{if .Alias}}{{.Alias}}.{{end}}{{.Entry.Func.Name}}Module(),
+		{{if .Alias}}{{.Alias}}.{{end}}{{.Name}}Module{{$.Suffix}}(),
+{{- end}}
+{{if eq .Type "SUPPLY"}}
+		fx.Supply(
+{{- if or .Supply.Name .Supply.Group}}
+			fx.Annotated{
+{{- if .Supply.Name}}
+				Name:   "{{.Supply.Name}}",
+{{- end}}
+{{- if .Supply.Group}}
+				Group:  "{{.Supply.Group}}",
 {{- end}}
+				Target: {{.Target}},
+			},
+{{- else}}
+			{{.Target}},
+{{- end}}
+		),
+{{else if .Hook}}
+		fx.Invoke(
+{{- if .ParamTags}}
+			fx.Annotate(
+				func(lc fx.Lifecycle, r {{.Hook.Type}}) {
+					lc.Append(fx.Hook{ {{.Hook.Event}}: {{.Hook.Func}} })
+				},
+				fx.ParamTags({{range .ParamTags}}{{.}}, {{end}}),
+			),
+{{- else}}
+			func(lc fx.Lifecycle, r {{.Hook.Type}}) {
+				lc.Append(fx.Hook{ {{.Hook.Event}}: {{.Hook.Func}} })
+			},
+{{- end}}
+		),
+{{else}}
 {{if eq .Type "PROVIDE"}}
 		fx.Provide(
-			{{.Alias}}.{{.FunctionName}},
+{{else if eq .Type "DECORATE"}}
+		fx.Decorate(
 {{else}}
 		fx.Invoke(
-			{{.Alias}}.{{.FunctionName}},
 {{end}}
+{{- if or .ParamTags .ResultTags .As}}
+			fx.Annotate(
+				{{.Target}},
+{{- if .ParamTags}}
+				fx.ParamTags({{range .ParamTags}}{{.}}, {{end}}),
+{{- end}}
+{{- if .ResultTags}}
+				fx.ResultTags({{range .ResultTags}}{{.}}, {{end}}),
+{{- end}}
+{{- if .As}}
+				fx.As({{range .As}}{{.}}, {{end}}),
+{{- end}}
+{{- if .AsSelf}}
+				fx.As(fx.Self()),
+{{- end}}
+			),
+{{- else}}
+			{{.Target}},
+{{- end}}
+{{- if .Private}}
+			fx.Private,
+{{- end}}
 		),
+{{end}}
+	)
+	})
+	return options
+}
+`
+
+const namedModuleTemplate = `// Code generated by inject; DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+{{- range .Imports}}
+	{{.Alias}} "{{.Path}}"
+{{- end}}
+	"sync"
+	"go.uber.org/fx"
+)
+
+var {{.ModuleName}}Once{{.Suffix}} sync.Once
+
+func {{.ModuleName}}Module{{.Suffix}}() fx.Option {
+	options := fx.Options()
+
+	{{.ModuleName}}Once{{.Suffix}}.Do(func() {
+	options = fx.Module("{{.Name}}",
+{{- range .Modules}}
+		{{if .Alias}}{{.Alias}}.{{end}}{{.Name}}Module{{$.Suffix}}(),
+{{- end}}
 	)
 	})
 	return options
 }
 `
 
+const replaceTemplate = `// Code generated by inject; DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	"go.uber.org/fx"
+)
+
+// ReplaceModule{{.Suffix}} returns the replacements declared in the tests of
+// this package, to be passed along with the modules they override.
+func ReplaceModule{{.Suffix}}() fx.Option {
+	return fx.Options(
+{{- range .Replacements}}
+{{- if .Value}}
+		fx.Replace(
+{{- if or .An.Name .An.Group}}
+			fx.Annotated{
+{{- if .An.Name}}
+				Name:   "{{.An.Name}}",
+{{- end}}
+{{- if .An.Group}}
+				Group:  "{{.An.Group}}",
+{{- end}}
+				Target: {{.Target}},
+			},
+{{- else}}
+			{{.Target}},
+{{- end}}
+		),
+{{- else}}
+		fx.Decorate(
+{{- if or .ParamTags .ResultTags}}
+			fx.Annotate(
+				{{.Target}},
+{{- if .ParamTags}}
+				fx.ParamTags({{range .ParamTags}}{{.}}, {{end}}),
+{{- end}}
+{{- if .ResultTags}}
+				fx.ResultTags({{range .ResultTags}}{{.}}, {{end}}),
+{{- end}}
+			),
+{{- else}}
+			{{.Target}},
+{{- end}}
+		),
+{{- end}}
+{{- end}}
+	)
+}
+`
+
 type ModuleData struct {
 	PackageName  string
 	FunctionName string
+	Target       string
 	ImportPath   string
 	Modules      []ImportData
 	Imports      []ImportData
 	Alias        string
 	Entry        annotation.Entry
 	Type         string
+	ParamTags    []string
+	ResultTags   []string
+	As           []string
+	AsSelf       bool
+	TypeImports  []ImportData
+	Supply       Annotation
+	Hook         *HookData
+	Private      bool
+	Suffix       string
+}
+
+// HookData describes the fx.Hook appended by a lifecycle hook module.
+type HookData struct {
+	Event   string // OnStart or OnStop.
+	Type    string // bound type, qualified for the generated module.
+	Func    string // expression of the hook function.
+	Context bool   // whether the expression requires the context package.
+}
+
+type NamedModuleData struct {
+	PackageName string
+	Name        string
+	ModuleName  string
+	Suffix      string
+	Modules     []ImportData
+	Imports     []ImportData
+}
+
+type ReplaceModuleData struct {
+	PackageName  string
+	Suffix       string
+	Replacements []ReplaceData
+}
+
+// ReplaceData describes a single replacement. Values are replaced as they are,
+// while functions decorate their target, ignoring the replaced value.
+type ReplaceData struct {
+	Target     string
+	Value      bool
+	An         Annotation
+	ParamTags  []string
+	ResultTags []string
 }
 
 type ImportData struct {
 	Alias string
 	Path  string
+	Name  string
 	Entry annotation.Entry
 }
 
@@ -71,3 +255,19 @@ func NewTemplate() (*template.Template, error) {
 	}
 	return tmpl, nil
 }
+
+func NewNamedModuleTemplate() (*template.Template, error) {
+	tmpl, err := template.New("namedModule").Parse(namedModuleTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %v", err)
+	}
+	return tmpl, nil
+}
+
+func NewReplaceTemplate() (*template.Template, error) {
+	tmpl, err := template.New("replace").Parse(replaceTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %v", err)
+	}
+	return tmpl, nil
+}
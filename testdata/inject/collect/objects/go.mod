@@ -0,0 +1,3 @@
+module github.com/acme/objects
+
+go 1.22
@@ -0,0 +1,22 @@
+package user
+
+import (
+	uberfx "go.uber.org/fx"
+)
+
+type Params struct {
+	uberfx.In
+
+	Reader   *Repo     `name:"read"`
+	Cache    *Cache    `optional:"true"`
+	Handlers []Handler `group:"handlers"`
+}
+
+type Result struct {
+	uberfx.Out
+
+	Reader, Writer *Repo     `name:"rw"`
+	Handlers       []Handler `group:"handlers,flatten"`
+}
+
+type Repo struct{}
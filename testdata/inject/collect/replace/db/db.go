@@ -0,0 +1,9 @@
+package db
+
+type Client struct{}
+
+// NewClient title
+// @Provide (index=0)
+func NewClient() *Client {
+	return &Client{}
+}
@@ -0,0 +1,15 @@
+package db
+
+// FakeClient title
+// @Replace (target=*Client)
+var FakeClient = &Client{}
+
+// newFakeCache title
+// @Inject (name=cfg, index=1)
+// @Replace (target=*Cache, name=cache)
+func newFakeCache(client *Client, cfg Config) (*Cache, error) {
+	return &Cache{}, nil
+}
+
+// helper title
+func helper() {}
@@ -0,0 +1,3 @@
+module github.com/acme/replace
+
+go 1.22
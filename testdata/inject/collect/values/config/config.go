@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+// DefaultTimeout title
+// @Supply (name=defaultTimeout)
+var DefaultTimeout time.Duration = 5 * time.Second
+
+// MaxRetries title
+// @Supply (name=maxRetries, type=int)
+const MaxRetries = 3
+
+var (
+	// Region title
+	// @Supply (group=regions)
+	Region string = "us-east-1"
+
+	// notSupplied title
+	notSupplied = "value"
+)
@@ -0,0 +1,3 @@
+module github.com/acme/values
+
+go 1.22
@@ -0,0 +1,13 @@
+package app
+
+import (
+	store "github.com/acme/resolve/db"
+	legacy "github.com/acme/resolve/legacy/db"
+)
+
+type Service struct{}
+
+var (
+	_ store.Client
+	_ legacy.Client
+)
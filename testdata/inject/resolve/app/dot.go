@@ -0,0 +1,7 @@
+package app
+
+import (
+	. "github.com/acme/resolve/db"
+)
+
+var _ Conn
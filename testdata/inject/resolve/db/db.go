@@ -0,0 +1,5 @@
+package db
+
+type Client struct{}
+
+type Conn = Client
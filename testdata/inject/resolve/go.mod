@@ -0,0 +1,3 @@
+module github.com/acme/resolve
+
+go 1.22
@@ -0,0 +1,3 @@
+package db
+
+type Client struct{}
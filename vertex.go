@@ -17,16 +17,11 @@ func (v *Vertex[T]) Adjacent() []*Vertex[T] {
 	return nil
 }
 
-// Incoming returns a list of vertices with edges incoming to this vertex.
-// It iterates over all edges in the graph to find incoming connections.
+// Incoming returns a list of vertices with edges incoming to this vertex, sorted by key.
+// It returns nil if the vertex is not part of a graph.
 func (v *Vertex[T]) Incoming() []*Vertex[T] {
-	var incomingVertices []*Vertex[T]
-	for key, edges := range v.graph.edges {
-		for _, edge := range edges {
-			if edge.Key == v.Key {
-				incomingVertices = append(incomingVertices, v.graph.vertices[key])
-			}
-		}
+	if v.graph != nil {
+		return v.graph.incoming[v.Key]
 	}
-	return incomingVertices
+	return nil
 }
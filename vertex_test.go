@@ -1,6 +1,8 @@
 package inject
 
 import (
+	"fmt"
+	ulog "github.com/americanas-go/log"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -73,3 +75,100 @@ func (suite *VertexTestSuite) TestIncoming() {
 		})
 	}
 }
+
+func (suite *VertexTestSuite) TestIncomingSorted() {
+	g := newGraph([][2]string{{"e", "a"}, {"c", "a"}, {"b", "a"}})
+	suite.Equal([]string{"b", "c", "e"}, keysOf(g.vertices["a"].Incoming()))
+}
+
+// discardLogs discards the logs until the benchmark ends, so they don't weigh
+// on it.
+func discardLogs(b *testing.B) {
+	logger := log
+	WithLogger(ulog.NewNoop())
+	b.Cleanup(func() { WithLogger(logger) })
+}
+
+// newSyntheticGraph builds a graph of n vertices, each one linked to the next
+// fanout vertices, like layers of components depending on each other.
+func newSyntheticGraph(n int, fanout int) *Graph[int] {
+	g := NewGraph[int]()
+	for i := 0; i < n; i++ {
+		g.AddVertex(fmt.Sprintf("v%06d", i), i)
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j <= i+fanout && j < n; j++ {
+			g.AddEdge(fmt.Sprintf("v%06d", i), fmt.Sprintf("v%06d", j))
+		}
+	}
+
+	return g
+}
+
+// scanIncoming finds the incoming vertices by scanning every edge of the
+// graph, as done before the reverse index, to compare against it.
+func scanIncoming[T any](v *Vertex[T]) []*Vertex[T] {
+	var incomingVertices []*Vertex[T]
+	for key, edges := range v.graph.edges {
+		for _, edge := range edges {
+			if edge.Key == v.Key {
+				incomingVertices = append(incomingVertices, v.graph.vertices[key])
+			}
+		}
+	}
+	return incomingVertices
+}
+
+func BenchmarkIncoming(b *testing.B) {
+	discardLogs(b)
+	g := newSyntheticGraph(10000, 4)
+	b.ResetTimer()
+	vertices := g.sortedKeys()
+
+	b.Run("Index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.vertices[vertices[i%len(vertices)]].Incoming()
+		}
+	})
+
+	b.Run("Scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			scanIncoming(g.vertices[vertices[i%len(vertices)]])
+		}
+	})
+}
+
+func BenchmarkIncomingAllVertices(b *testing.B) {
+	discardLogs(b)
+	g := newSyntheticGraph(10000, 4)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, v := range g.vertices {
+			v.Incoming()
+		}
+	}
+}
+
+func BenchmarkAddEdge(b *testing.B) {
+	discardLogs(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		newSyntheticGraph(10000, 4)
+	}
+}
+
+func BenchmarkTopologicalSort(b *testing.B) {
+	discardLogs(b)
+	g := newSyntheticGraph(10000, 4)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := g.TopologicalSort()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}